@@ -1,3 +1,6 @@
+// landlock-abi-version prints the Landlock ABI version supported by
+// the running kernel, or 0 if Landlock is not supported.  See
+// landlock-info for a more detailed report.
 package main
 
 import (
@@ -0,0 +1,43 @@
+// landlock-info reports the Landlock support of the running kernel,
+// as a successor of landlock-abi-version.
+//
+// This is an example tool which does not provide backwards compatibility guarantees.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/landlock-lsm/go-landlock/landlock"
+)
+
+func usage() {
+	var (
+		out  = flag.CommandLine.Output()
+		name = os.Args[0]
+	)
+	fmt.Fprintf(out, "Usage of %s:\n", name)
+	flag.PrintDefaults()
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "\033[31;1m** This is a demo tool for go-landlock and will not provide backwards compatibility. **\033[0m")
+}
+
+func main() {
+	asJSON := flag.Bool("json", false, "print the report as JSON")
+	flag.Usage = usage
+	flag.Parse()
+
+	res := landlock.Probe()
+	if !*asJSON {
+		fmt.Print(res)
+		return
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(res); err != nil {
+		log.Fatal(err)
+	}
+}
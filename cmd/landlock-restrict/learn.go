@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/landlock-lsm/go-landlock/landlock"
+	"github.com/landlock-lsm/go-landlock/landlock/audit"
+)
+
+// learn runs the command in a sandbox with audit logging enabled, and
+// prints a policy which permits the denied accesses.  It returns the
+// exit code of the command.
+func learn(cfg landlock.Config, rules []landlock.Rule, cmdArgs []string, format string) int {
+	r, err := audit.DialNetlink()
+	if err != nil {
+		log.Fatalf("learning mode needs CAP_AUDIT_READ: %v", err)
+	}
+
+	var recs []audit.Record
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			rec, err := r.Read()
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					log.Printf("audit: %v", err)
+				}
+				return
+			}
+			recs = append(recs, rec)
+		}
+	}()
+
+	cmd := exec.Command(cmdArgs[0], cmdArgs[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cfg.EnableLoggingForSubprocesses().ApplyToCmd(cmd, rules...); err != nil {
+		log.Fatalf("landlock: %v", err)
+	}
+	err = cmd.Start()
+	for _, f := range cmd.ExtraFiles {
+		f.Close()
+	}
+	if err != nil {
+		log.Fatalf("start: %v", err)
+	}
+	exitCode := 0
+	if err := cmd.Wait(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			log.Fatalf("wait: %v", err)
+		}
+		exitCode = exitErr.ExitCode()
+	}
+
+	// Give the last audit records some time to arrive.
+	time.Sleep(200 * time.Millisecond)
+	r.Close()
+	<-done
+
+	var l audit.Learner
+	l.TrackPID(cmd.Process.Pid)
+	for _, rec := range recs {
+		l.Add(rec)
+	}
+	s := l.Suggest()
+
+	fmt.Fprintln(os.Stderr)
+	switch format {
+	case "json":
+		data, err := s.PolicyJSON()
+		if err != nil {
+			log.Fatalf("policy: %v", err)
+		}
+		fmt.Fprintf(os.Stderr, "%s\n", data)
+	default:
+		fmt.Fprint(os.Stderr, s.GoSource())
+	}
+	if s.Denials == 0 {
+		fmt.Fprintln(os.Stderr, "No Landlock denials were observed.  Is audit logging enabled in the kernel?")
+	}
+	return exitCode
+}
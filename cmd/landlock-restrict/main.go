@@ -1,5 +1,9 @@
 // landlock-restrict executes a process with Landlock filesystem restrictions.
 //
+// In learning mode (-learn or -learnjson), it instead runs the process
+// with all access rights restricted, observes the resulting denials in
+// the audit log, and prints a suggested policy.
+//
 // This is an example tool which does not provide backwards compatibility guarantees.
 package main
 
@@ -14,7 +18,7 @@ import (
 	"github.com/landlock-lsm/go-landlock/landlock"
 )
 
-func parseFlags(args []string) (verbose bool, cfg landlock.Config, opts []landlock.Rule, cmd []string) {
+func parseFlags(args []string) (verbose, deps bool, learnFormat string, cfg landlock.Config, opts []landlock.Rule, cmd []string) {
 	configs := []landlock.Config{landlock.V1, landlock.V2, landlock.V3, landlock.V4, landlock.V5, landlock.V6, landlock.V7, landlock.V8, landlock.V9}
 	cfg = configs[len(configs)-1]
 
@@ -79,6 +83,18 @@ ArgParsing:
 			verbose = true
 			args = args[1:]
 			continue
+		case "-deps":
+			deps = true
+			args = args[1:]
+			continue
+		case "-learn":
+			learnFormat = "go"
+			args = args[1:]
+			continue
+		case "-learnjson":
+			learnFormat = "json"
+			args = args[1:]
+			continue
 		case "-ro":
 			args = args[1:]
 			opts = append(opts, takeArgs(landlock.RODirs))
@@ -108,11 +124,13 @@ ArgParsing:
 	if bestEffort {
 		cfg = cfg.BestEffort()
 	}
-	return verbose, cfg, opts, cmd
+	return verbose, deps, learnFormat, cfg, opts, cmd
 }
 
 func main() {
-	verbose, cfg, opts, cmdArgs := parseFlags(os.Args[1:])
+	landlock.RunExecHelper() // For the -learn mode.
+
+	verbose, deps, learnFormat, cfg, opts, cmdArgs := parseFlags(os.Args[1:])
 	if verbose {
 		fmt.Println("Args: ", os.Args)
 		fmt.Println()
@@ -124,7 +142,7 @@ func main() {
 	if len(cmdArgs) < 1 {
 		fmt.Println("Usage:")
 		fmt.Println("  landlock-restrict")
-		fmt.Println("     [-v] [-l]")
+		fmt.Println("     [-v] [-l] [-deps] [-learn | -learnjson]")
 		fmt.Println("     [-1] [-2] [-3] [-4] [-5] [-6] [-7] [-8] [-9] [-strict]")
 		fmt.Println("     [-ro [+refer] PATH...]")
 		fmt.Println("     [-rw [+refer] [+ioctl_dev] [+resolve_unix] PATH...]")
@@ -138,6 +156,9 @@ func main() {
 		fmt.Println("  -strict                            use strict mode (instead of best effort)")
 		fmt.Println("  -v                                 verbose logging")
 		fmt.Println("  -l                                 audit logging for subprocess")
+		fmt.Println("  -deps                              grant access to the command and its shared libraries")
+		fmt.Println("  -learn, -learnjson                 learning mode: suggest rules from audit denials,")
+		fmt.Println("                                     as Go code or policy file (needs CAP_AUDIT_READ)")
 		fmt.Println()
 		fmt.Println("A path list that contains the word '+refer' will additionally grant the refer access right.")
 		fmt.Println()
@@ -152,6 +173,21 @@ func main() {
 		log.Fatalf("Need absolute binary path, got %q", cmdArgs[0])
 	}
 
+	if deps {
+		rule, err := landlock.ExecutableDeps(cmdArgs[0])
+		if err != nil {
+			log.Fatalf("landlock: %v", err)
+		}
+		if verbose {
+			fmt.Println("Path option:", rule)
+		}
+		opts = append(opts, rule)
+	}
+
+	if learnFormat != "" {
+		os.Exit(learn(cfg, opts, cmdArgs, learnFormat))
+	}
+
 	err := cfg.RestrictPaths(opts...)
 	if err != nil {
 		log.Fatalf("landlock: %v", err)
@@ -3,6 +3,7 @@ module github.com/landlock-lsm/go-landlock
 go 1.24.0
 
 require (
+	github.com/BurntSushi/toml v1.6.0
 	golang.org/x/sys v0.40.0
 	kernel.org/pub/linux/libs/security/libcap/psx v1.2.77
 )
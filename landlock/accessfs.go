@@ -2,6 +2,7 @@ package landlock
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -30,6 +31,14 @@ type AccessFSSet uint64
 
 var supportedAccessFS = AccessFSSet((1 << len(accessFSNames)) - 1)
 
+// accessFSAliases are the group names accepted by ParseAccessFSSet.
+var accessFSAliases = map[string]uint64{
+	"all":        uint64(supportedAccessFS),
+	"read":       uint64(accessFSRead),
+	"write":      uint64(accessFSWrite),
+	"read_write": uint64(accessFSReadWrite),
+}
+
 func accessSetString(a uint64, names []string) string {
 	if a == 0 {
 		return "∅"
@@ -53,10 +62,107 @@ func accessSetString(a uint64, names []string) string {
 	return b.String()
 }
 
+// accessSetSyntax describes the names which are accepted when
+// parsing a set of access rights.
+type accessSetSyntax struct {
+	names        []string          // names of individual bits, as in String()
+	kernelPrefix string            // prefix of the C constant names
+	aliases      map[string]uint64 // names of groups of bits
+}
+
+// parseName parses a single element of an access set.
+func (syn accessSetSyntax) parseName(name string) (uint64, error) {
+	name = strings.TrimSpace(name)
+	if v, ok := syn.aliases[name]; ok {
+		return v, nil
+	}
+	if n, ok := strings.CutPrefix(name, syn.kernelPrefix); ok {
+		name = strings.ToLower(n)
+	}
+	for i, n := range syn.names {
+		if n == name {
+			return 1 << i, nil
+		}
+	}
+	if n, ok := strings.CutPrefix(name, "1<<"); ok {
+		if i, err := strconv.ParseUint(n, 10, 6); err == nil {
+			return 1 << i, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown access right %q", name)
+}
+
+// parse parses the representation returned by accessSetString, as
+// well as comma-separated lists without the surrounding braces.
+func (syn accessSetSyntax) parse(s string) (uint64, error) {
+	s = strings.TrimSpace(s)
+	if s == "∅" {
+		return 0, nil
+	}
+	if inner, ok := strings.CutPrefix(s, "{"); ok {
+		inner, ok = strings.CutSuffix(inner, "}")
+		if !ok {
+			return 0, fmt.Errorf("missing closing brace in %q", s)
+		}
+		s = inner
+	}
+	if strings.TrimSpace(s) == "" {
+		return 0, nil
+	}
+	var a uint64
+	for name := range strings.SplitSeq(s, ",") {
+		v, err := syn.parseName(name)
+		if err != nil {
+			return 0, err
+		}
+		a |= v
+	}
+	return a, nil
+}
+
+var accessFSSyntax = accessSetSyntax{
+	names:        accessFSNames,
+	kernelPrefix: "LANDLOCK_ACCESS_FS_",
+	aliases:      accessFSAliases,
+}
+
 func (a AccessFSSet) String() string {
 	return accessSetString(uint64(a), accessFSNames)
 }
 
+// ParseAccessFSSet parses a set of filesystem access rights.
+//
+// It accepts the format returned by [AccessFSSet.String], such as
+// "{read_file,write_file}" or "∅", as well as comma-separated lists
+// without braces.  The individual access rights may also be spelled
+// like the kernel constants (e.g. "LANDLOCK_ACCESS_FS_READ_FILE"), and
+// the following group names are accepted:
+//
+//   - "read": the access rights granted by [RODirs]
+//   - "write": the write access rights granted by [RWDirs]
+//   - "read_write": the access rights granted by [RWDirs]
+//   - "all": all access rights supported by this version of Go-Landlock
+func ParseAccessFSSet(s string) (AccessFSSet, error) {
+	a, err := accessFSSyntax.parse(s)
+	return AccessFSSet(a), err
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (a AccessFSSet) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+// It accepts the same format as [ParseAccessFSSet].
+func (a *AccessFSSet) UnmarshalText(text []byte) error {
+	v, err := ParseAccessFSSet(string(text))
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
+
 func (a AccessFSSet) isSubset(b AccessFSSet) bool {
 	return a&b == a
 }
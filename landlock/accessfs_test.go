@@ -69,3 +69,124 @@ func TestValid(t *testing.T) {
 		}
 	}
 }
+
+func TestParseAccessFSSet(t *testing.T) {
+	for _, tc := range []struct {
+		s    string
+		want AccessFSSet
+	}{
+		{s: "∅", want: 0},
+		{s: "{}", want: 0},
+		{s: "", want: 0},
+		{s: "{read_file}", want: ll.AccessFSReadFile},
+		{s: "{read_file,write_file}", want: ll.AccessFSReadFile | ll.AccessFSWriteFile},
+		{s: "read_file, write_file", want: ll.AccessFSReadFile | ll.AccessFSWriteFile},
+		{s: "LANDLOCK_ACCESS_FS_MAKE_SYM,refer", want: ll.AccessFSMakeSym | ll.AccessFSRefer},
+		{s: "read", want: accessFSRead},
+		{s: "{write,ioctl_dev}", want: accessFSWrite | ll.AccessFSIoctlDev},
+		{s: "read_write", want: accessFSReadWrite},
+		{s: "all", want: supportedAccessFS},
+		{s: "{read_file,1<<63}", want: ll.AccessFSReadFile | 1<<63},
+	} {
+		got, err := ParseAccessFSSet(tc.s)
+		if err != nil {
+			t.Errorf("ParseAccessFSSet(%q): %v", tc.s, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("ParseAccessFSSet(%q) = %v, want %v", tc.s, got, tc.want)
+		}
+	}
+}
+
+func TestParseAccessFSSetErrors(t *testing.T) {
+	for _, s := range []string{
+		"{read_file",
+		"red_file",
+		"read_file,,write_file",
+		"LANDLOCK_ACCESS_NET_BIND_TCP",
+		"1<<64",
+	} {
+		if got, err := ParseAccessFSSet(s); err == nil {
+			t.Errorf("ParseAccessFSSet(%q) = %v, want error", s, got)
+		}
+	}
+}
+
+func TestAccessFSSetRoundTrip(t *testing.T) {
+	for _, a := range []AccessFSSet{
+		0,
+		ll.AccessFSExecute,
+		accessFSReadWrite,
+		supportedAccessFS,
+		ll.AccessFSReadFile | 1<<63,
+	} {
+		text, err := a.MarshalText()
+		if err != nil {
+			t.Fatalf("%v.MarshalText(): %v", a, err)
+		}
+		var got AccessFSSet
+		if err := got.UnmarshalText(text); err != nil {
+			t.Errorf("UnmarshalText(%q): %v", text, err)
+		}
+		if got != a {
+			t.Errorf("UnmarshalText(%q) = %v, want %v", text, got, a)
+		}
+	}
+}
+
+func TestParseAccessNetSet(t *testing.T) {
+	for _, tc := range []struct {
+		s    string
+		want AccessNetSet
+	}{
+		{s: "∅", want: 0},
+		{s: "{bind_tcp}", want: ll.AccessNetBindTCP},
+		{s: "{bind_tcp,connect_tcp}", want: ll.AccessNetBindTCP | ll.AccessNetConnectTCP},
+		{s: "LANDLOCK_ACCESS_NET_CONNECT_TCP", want: ll.AccessNetConnectTCP},
+		{s: "all", want: supportedAccessNet},
+	} {
+		got, err := ParseAccessNetSet(tc.s)
+		if err != nil {
+			t.Errorf("ParseAccessNetSet(%q): %v", tc.s, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("ParseAccessNetSet(%q) = %v, want %v", tc.s, got, tc.want)
+		}
+		if rt, err := ParseAccessNetSet(got.String()); err != nil || rt != got {
+			t.Errorf("ParseAccessNetSet(%q) = %v, %v; want %v", got.String(), rt, err, got)
+		}
+	}
+	if got, err := ParseAccessNetSet("read_file"); err == nil {
+		t.Errorf("ParseAccessNetSet(%q) = %v, want error", "read_file", got)
+	}
+}
+
+func TestParseScopedSet(t *testing.T) {
+	for _, tc := range []struct {
+		s    string
+		want ScopedSet
+	}{
+		{s: "∅", want: 0},
+		{s: "{signal}", want: ll.ScopeSignal},
+		{s: "{abstract_unix_socket,signal}", want: ll.ScopeAbstractUnixSocket | ll.ScopeSignal},
+		{s: "LANDLOCK_SCOPE_ABSTRACT_UNIX_SOCKET", want: ll.ScopeAbstractUnixSocket},
+		{s: "all", want: supportedScoped},
+	} {
+		got, err := ParseScopedSet(tc.s)
+		if err != nil {
+			t.Errorf("ParseScopedSet(%q): %v", tc.s, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("ParseScopedSet(%q) = %v, want %v", tc.s, got, tc.want)
+		}
+		if rt, err := ParseScopedSet(got.String()); err != nil || rt != got {
+			t.Errorf("ParseScopedSet(%q) = %v, %v; want %v", got.String(), rt, err, got)
+		}
+	}
+	if got, err := ParseScopedSet("bind_tcp"); err == nil {
+		t.Errorf("ParseScopedSet(%q) = %v, want error", "bind_tcp", got)
+	}
+}
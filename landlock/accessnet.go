@@ -10,10 +10,45 @@ var accessNetNames = []string{
 
 var supportedAccessNet = AccessNetSet((1 << len(accessNetNames)) - 1)
 
+var accessNetSyntax = accessSetSyntax{
+	names:        accessNetNames,
+	kernelPrefix: "LANDLOCK_ACCESS_NET_",
+	aliases:      map[string]uint64{"all": uint64(supportedAccessNet)},
+}
+
 func (a AccessNetSet) String() string {
 	return accessSetString(uint64(a), accessNetNames)
 }
 
+// ParseAccessNetSet parses a set of network access rights.
+//
+// It accepts the format returned by [AccessNetSet.String], such as
+// "{bind_tcp,connect_tcp}" or "∅", as well as comma-separated lists
+// without braces.  The individual access rights may also be spelled
+// like the kernel constants (e.g. "LANDLOCK_ACCESS_NET_BIND_TCP"), and
+// "all" denotes all network access rights supported by this version of
+// Go-Landlock.
+func ParseAccessNetSet(s string) (AccessNetSet, error) {
+	a, err := accessNetSyntax.parse(s)
+	return AccessNetSet(a), err
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (a AccessNetSet) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+// It accepts the same format as [ParseAccessNetSet].
+func (a *AccessNetSet) UnmarshalText(text []byte) error {
+	v, err := ParseAccessNetSet(string(text))
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
+
 func (a AccessNetSet) isSubset(b AccessNetSet) bool {
 	return a&b == a
 }
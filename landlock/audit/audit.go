@@ -0,0 +1,127 @@
+// Package audit decodes the audit log records which the Linux kernel
+// emits for Landlock denials.
+//
+// Starting with Landlock ABI V7, the kernel logs denied accesses as
+// AUDIT_LANDLOCK_ACCESS records, and the creation and destruction of
+// Landlock domains as AUDIT_LANDLOCK_DOMAIN records.  The situations
+// in which this happens can be configured using
+// [landlock.Config.DisableLoggingForOriginatingProcess],
+// [landlock.Config.EnableLoggingForSubprocesses] and
+// [landlock.Config.DisableLoggingForSubdomains].
+//
+// A [Reader] decodes these records from audit log files (as written by
+// auditd), from kernel log output (as shown by dmesg(1) or
+// journalctl(1) when auditd is not running), or directly from the
+// kernel's audit netlink socket (see [DialNetlink]).
+//
+// The kernel documentation describes the records in more detail at
+// https://docs.kernel.org/admin-guide/LSM/landlock.html.
+package audit
+
+import (
+	"time"
+
+	"github.com/landlock-lsm/go-landlock/landlock"
+)
+
+// Audit record types used by Landlock.
+const (
+	TypeSyscall        = 1300 // AUDIT_SYSCALL
+	TypeEOE            = 1320 // AUDIT_EOE, end of a multi-record event
+	TypeLandlockAccess = 1423 // AUDIT_LANDLOCK_ACCESS
+	TypeLandlockDomain = 1424 // AUDIT_LANDLOCK_DOMAIN
+)
+
+// Record is a decoded Landlock audit record.
+// It is either an [*Access] or a [*Domain].
+type Record interface {
+	isRecord()
+}
+
+// Header holds the fields which are common to all audit records.
+type Header struct {
+	// Time is the time of the audit event.
+	Time time.Time
+
+	// Serial is the audit event serial number.  Records with the
+	// same serial number belong to the same event.
+	Serial uint64
+
+	// Fields holds all key-value pairs of the record, with
+	// quoted and hex-encoded values decoded.
+	Fields map[string]string
+}
+
+// Access is a decoded AUDIT_LANDLOCK_ACCESS record, which describes a
+// denied access.
+type Access struct {
+	Header
+
+	// Domain is the ID of the Landlock domain which denied the
+	// access.
+	Domain uint64
+
+	// Blockers are the reasons for the denial as spelled in the
+	// record, e.g. "fs.write_file" or "net.connect_tcp".
+	Blockers []string
+
+	// The blocked access rights and scopes, for the blockers that
+	// correspond to Go-Landlock access rights.
+	AccessFS  landlock.AccessFSSet
+	AccessNet landlock.AccessNetSet
+	Scoped    landlock.ScopedSet
+
+	// Path, Dev and Ino describe the file for filesystem denials.
+	// They are empty if not applicable.
+	Path string
+	Dev  string
+	Ino  uint64
+
+	// Addr and Port describe the socket address for network
+	// denials.  For bind(2), these are the source address and port,
+	// for connect(2), these are the destination address and port.
+	Addr string
+	Port uint16
+
+	// PID, Comm and Exe describe the process which caused the
+	// denial.  They are taken from the SYSCALL record which belongs
+	// to the same audit event, and are zero if that record is not
+	// available.
+	PID  int
+	Comm string
+	Exe  string
+}
+
+func (*Access) isRecord() {}
+
+// Domain statuses in AUDIT_LANDLOCK_DOMAIN records.
+const (
+	StatusAllocated   = "allocated"
+	StatusDeallocated = "deallocated"
+)
+
+// Domain is a decoded AUDIT_LANDLOCK_DOMAIN record, which describes
+// the creation or destruction of a Landlock domain.
+type Domain struct {
+	Header
+
+	// Domain is the ID of the Landlock domain.
+	Domain uint64
+
+	// Status is either [StatusAllocated] or [StatusDeallocated].
+	Status string
+
+	// For allocated domains: The enforcement mode and the process
+	// which created the domain.
+	Mode string
+	PID  int
+	UID  int
+	Exe  string
+	Comm string
+
+	// For deallocated domains: The number of denials which
+	// happened in the domain.
+	Denials uint64
+}
+
+func (*Domain) isRecord() {}
@@ -0,0 +1,207 @@
+package audit
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/landlock-lsm/go-landlock/landlock"
+	ll "github.com/landlock-lsm/go-landlock/landlock/syscall"
+)
+
+func readFixture(t *testing.T, name string) []Record {
+	t.Helper()
+	f, err := os.Open("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	recs, err := NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	// The raw fields are checked separately.
+	for _, rec := range recs {
+		switch r := rec.(type) {
+		case *Access:
+			r.Fields = nil
+		case *Domain:
+			r.Fields = nil
+		}
+	}
+	return recs
+}
+
+func hdr(sec, msec int64, serial uint64) Header {
+	return Header{Time: time.Unix(sec, msec*1e6), Serial: serial}
+}
+
+func checkRecords(t *testing.T, got, want []Record) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d records, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("record %d:\n got %+v\nwant %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestReadAuditdLog(t *testing.T) {
+	const domain = 0x1a6fdc66f
+	checkRecords(t, readFixture(t, "audit.log"), []Record{
+		&Access{
+			Header:   hdr(1729738800, 268, 30),
+			Domain:   domain,
+			Blockers: []string{"fs.write_file"},
+			AccessFS: ll.AccessFSWriteFile,
+			Path:     "/dev/tty",
+			Dev:      "devtmpfs",
+			Ino:      9,
+			PID:      286,
+			Comm:     "sandboxer",
+			Exe:      "/root/sandboxer",
+		},
+		&Domain{
+			Header: hdr(1729738800, 268, 30),
+			Domain: domain,
+			Status: StatusAllocated,
+			Mode:   "enforcing",
+			PID:    286,
+			UID:    0,
+			Exe:    "/root/sandboxer",
+			Comm:   "sandboxer",
+		},
+		&Access{
+			Header:    hdr(1729738800, 324, 32),
+			Domain:    domain,
+			Blockers:  []string{"net.connect_tcp"},
+			AccessNet: ll.AccessNetConnectTCP,
+			Addr:      "127.0.0.1",
+			Port:      80,
+			PID:       287,
+			Comm:      "curl",
+			Exe:       "/usr/bin/curl",
+		},
+		&Access{
+			Header:   hdr(1729738800, 401, 33),
+			Domain:   domain,
+			Blockers: []string{"fs.make_reg", "fs.refer"},
+			AccessFS: ll.AccessFSMakeReg | ll.AccessFSRefer,
+			Path:     "/tmp/a b", // hex-encoded
+			Dev:      "tmpfs",
+			Ino:      17,
+			PID:      288,
+			Comm:     "mv a b", // hex-encoded
+			Exe:      "/usr/bin/mv",
+		},
+		&Access{
+			Header:   hdr(1729738800, 401, 33),
+			Domain:   domain,
+			Blockers: []string{"fs.remove_file"},
+			AccessFS: ll.AccessFSRemoveFile,
+			Path:     "/tmp",
+			Dev:      "tmpfs",
+			Ino:      1,
+			PID:      288,
+			Comm:     "mv a b",
+			Exe:      "/usr/bin/mv",
+		},
+		&Access{
+			Header:   hdr(1729738800, 500, 34),
+			Domain:   domain,
+			Blockers: []string{"scope.signal"},
+			Scoped:   ll.ScopeSignal,
+		},
+		&Domain{
+			Header:  hdr(1729738800, 612, 35),
+			Domain:  domain,
+			Status:  StatusDeallocated,
+			Denials: 5,
+		},
+	})
+}
+
+func TestReadKernelLog(t *testing.T) {
+	checkRecords(t, readFixture(t, "dmesg.log"), []Record{
+		&Access{
+			Header:   hdr(1729738800, 268, 30),
+			Domain:   0x1a6fdc66f,
+			Blockers: []string{"fs.read_file"},
+			AccessFS: ll.AccessFSReadFile,
+			Path:     "/etc/passwd",
+			Dev:      "vda2",
+			Ino:      1234,
+			PID:      286,
+			Comm:     "cat",
+			Exe:      "/usr/bin/cat",
+		},
+		&Domain{
+			Header: hdr(1729738800, 268, 30),
+			Domain: 0x1a6fdc66f,
+			Status: StatusAllocated,
+			Mode:   "enforcing",
+			PID:    286,
+			UID:    1000,
+			Exe:    "/usr/bin/cat",
+			Comm:   "cat",
+		},
+	})
+}
+
+func TestReadEnrichedLog(t *testing.T) {
+	checkRecords(t, readFixture(t, "enriched.log"), []Record{
+		&Access{
+			Header:    hdr(1729738800, 268, 30),
+			Domain:    0x1a6fdc66f,
+			Blockers:  []string{"net.bind_tcp"},
+			AccessNet: ll.AccessNetBindTCP,
+			Addr:      "0.0.0.0",
+			Port:      8080,
+		},
+	})
+}
+
+func TestFields(t *testing.T) {
+	const line = `type=LANDLOCK_ACCESS msg=audit(1729738800.5:7): domain=1 blockers=scope.abstract_unix_socket path=00666F6F opid=42 ocomm="a b"`
+	rec, err := NewReader(strings.NewReader(line)).Read()
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	got := rec.(*Access).Fields
+	want := map[string]string{
+		"domain":   "1",
+		"blockers": "scope.abstract_unix_socket",
+		"path":     "\x00foo",
+		"opid":     "42",
+		"ocomm":    "a b",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Fields = %v, want %v", got, want)
+	}
+	if got, want := rec.(*Access).Scoped, landlock.ScopedSet(ll.ScopeAbstractUnixSocket); got != want {
+		t.Errorf("Scoped = %v, want %v", got, want)
+	}
+	if got, want := rec.(*Access).Time, time.Unix(1729738800, 5e8); !got.Equal(want) {
+		t.Errorf("Time = %v, want %v", got, want)
+	}
+}
+
+func TestParseLineRejects(t *testing.T) {
+	for _, line := range []string{
+		"",
+		"hello world",
+		"type=LANDLOCK_ACCESS msg=foo",
+		"type=UNKNOWN[abc] msg=audit(1.0:1): x=y",
+		"type=LANDLOCK_ACCESS msg=audit(1.0): x=y",
+		"type=LANDLOCK_ACCESS msg=audit(x.0:1): x=y",
+	} {
+		if _, ok := parseLine(line); ok {
+			t.Errorf("parseLine(%q) succeeded, want failure", line)
+		}
+	}
+}
@@ -0,0 +1,397 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/landlock-lsm/go-landlock/landlock"
+	ll "github.com/landlock-lsm/go-landlock/landlock/syscall"
+)
+
+// dirAccessFS are the access rights for which the path in an
+// AUDIT_LANDLOCK_ACCESS record is the directory in which the operation
+// happened.  For the other access rights, the path is the file itself.
+const dirAccessFS = ll.AccessFSReadDir | ll.AccessFSRemoveDir | ll.AccessFSRemoveFile |
+	ll.AccessFSMakeChar | ll.AccessFSMakeDir | ll.AccessFSMakeReg | ll.AccessFSMakeSock |
+	ll.AccessFSMakeFifo | ll.AccessFSMakeBlock | ll.AccessFSMakeSym | ll.AccessFSRefer
+
+// A Learner proposes a Landlock policy from observed denials.
+//
+// The intended workflow is to run a workload under a configuration
+// which handles all access rights (e.g. [landlock.V9]) with
+// [landlock.Config.EnableLoggingForSubprocesses], to pass the
+// resulting audit records to [Learner.Add], and to review the policy
+// returned by [Learner.Suggest].
+//
+// The zero value is a Learner which learns from all domains, with the
+// default path collapsing settings.
+type Learner struct {
+	// MinDepth is the minimum number of path components which a
+	// directory must have, for other directories to be collapsed
+	// into it.  The default is 2, so that for example /usr/lib may
+	// be suggested, but not /usr.
+	MinDepth int
+
+	// CollapseThreshold is the number of subdirectories of a
+	// directory which need to be suggested individually, before
+	// they get collapsed into a single suggestion for the parent
+	// directory.  The default is 3.
+	CollapseThreshold int
+
+	pids     map[int]bool
+	domains  map[uint64]bool
+	accesses []*Access
+}
+
+// TrackPID restricts learning to the denials in Landlock domains
+// which were created by the process with the given PID.  It may be
+// called multiple times to track multiple processes.  If TrackPID is
+// never called, denials from all domains are taken into account.
+func (l *Learner) TrackPID(pid int) {
+	if l.pids == nil {
+		l.pids = make(map[int]bool)
+	}
+	l.pids[pid] = true
+}
+
+// Add adds an audit record to the learned information.
+func (l *Learner) Add(rec Record) {
+	switch r := rec.(type) {
+	case *Access:
+		l.accesses = append(l.accesses, r)
+	case *Domain:
+		if r.Status == StatusAllocated && l.pids[r.PID] {
+			if l.domains == nil {
+				l.domains = make(map[uint64]bool)
+			}
+			l.domains[r.Domain] = true
+		}
+	}
+}
+
+// Suggestion is a policy proposed by a [Learner].
+type Suggestion struct {
+	// FS are the proposed filesystem rules.
+	FS []FSSuggestion
+
+	// BindTCP and ConnectTCP are the TCP ports for which network
+	// rules are proposed.
+	BindTCP    []uint16
+	ConnectTCP []uint16
+
+	// Scoped are the IPC scopes in which denials were observed.
+	// These can not be permitted with rules; the workload needs to
+	// run without restricting them.
+	Scoped landlock.ScopedSet
+
+	// Unresolved are the blockers which can not be permitted with
+	// Landlock rules, e.g. "fs.change_topology".
+	Unresolved []string
+
+	// Denials is the number of denials which the suggestion is
+	// based on.
+	Denials int
+}
+
+// FSSuggestion is a proposed filesystem rule, granting the access
+// rights Access to the file hierarchies under Paths.
+type FSSuggestion struct {
+	Paths  []string
+	Access landlock.AccessFSSet
+}
+
+// Suggest returns the minimal policy which permits the denied
+// accesses, with paths collapsed to directory prefixes.
+func (l *Learner) Suggest() *Suggestion {
+	minDepth := l.MinDepth
+	if minDepth == 0 {
+		minDepth = 2
+	}
+	threshold := l.CollapseThreshold
+	if threshold == 0 {
+		threshold = 3
+	}
+
+	s := &Suggestion{}
+	dirs := make(map[string]landlock.AccessFSSet)
+	bind := make(map[uint16]bool)
+	connect := make(map[uint16]bool)
+	for _, a := range l.accesses {
+		if l.pids != nil && !l.domains[a.Domain] {
+			continue
+		}
+		s.Denials++
+		if a.AccessFS != 0 && a.Path != "" {
+			if dirAccess := a.AccessFS & dirAccessFS; dirAccess != 0 {
+				dirs[a.Path] |= dirAccess
+			}
+			if fileAccess := a.AccessFS &^ dirAccessFS; fileAccess != 0 {
+				dirs[filepath.Dir(a.Path)] |= fileAccess
+			}
+		}
+		if a.AccessNet&ll.AccessNetBindTCP != 0 {
+			bind[a.Port] = true
+		}
+		if a.AccessNet&ll.AccessNetConnectTCP != 0 {
+			connect[a.Port] = true
+		}
+		s.Scoped |= a.Scoped
+		for _, b := range a.Blockers {
+			if !blockerResolved(b) && !slices.Contains(s.Unresolved, b) {
+				s.Unresolved = append(s.Unresolved, b)
+			}
+		}
+	}
+
+	collapseDirs(dirs, minDepth, threshold)
+
+	// Group directories with the same access rights into one rule.
+	byAccess := make(map[landlock.AccessFSSet][]string)
+	for dir, access := range dirs {
+		byAccess[access] = append(byAccess[access], dir)
+	}
+	for access, paths := range byAccess {
+		slices.Sort(paths)
+		s.FS = append(s.FS, FSSuggestion{Paths: paths, Access: access})
+	}
+	slices.SortFunc(s.FS, func(a, b FSSuggestion) int {
+		return strings.Compare(a.Paths[0], b.Paths[0])
+	})
+	s.BindTCP = sortedPorts(bind)
+	s.ConnectTCP = sortedPorts(connect)
+	slices.Sort(s.Unresolved)
+	return s
+}
+
+// blockerResolved reports whether the blocker corresponds to an access
+// right or scope which is represented in a Suggestion.
+func blockerResolved(b string) bool {
+	kind, name, _ := strings.Cut(b, ".")
+	var err error
+	switch kind {
+	case "fs":
+		_, err = landlock.ParseAccessFSSet(name)
+	case "net":
+		_, err = landlock.ParseAccessNetSet(name)
+	case "scope":
+		_, err = landlock.ParseScopedSet(name)
+	default:
+		return false
+	}
+	return err == nil
+}
+
+// collapseDirs merges directories into one of their ancestors, if
+// the ancestor has at least minDepth components and if at least
+// threshold of its subdirectories contain directories from dirs.
+// Afterwards, it removes directories which are already covered by one
+// of their ancestors.
+func collapseDirs(dirs map[string]landlock.AccessFSSet, minDepth, threshold int) {
+	for {
+		// For each ancestor, the subdirectories containing entries.
+		branches := make(map[string]map[string]bool)
+		for dir := range dirs {
+			child := dir
+			for p := filepath.Dir(dir); p != child; child, p = p, filepath.Dir(p) {
+				if pathDepth(p) < minDepth {
+					break
+				}
+				if branches[p] == nil {
+					branches[p] = make(map[string]bool)
+				}
+				branches[p][child] = true
+			}
+		}
+		// Collapse into the deepest candidate first, to keep the
+		// suggestion as narrow as possible.
+		target := ""
+		for p, bs := range branches {
+			if len(bs) >= threshold && (target == "" || pathDepth(p) > pathDepth(target)) {
+				target = p
+			}
+		}
+		if target == "" {
+			break
+		}
+		for dir, access := range dirs {
+			if strings.HasPrefix(dir, target+"/") {
+				dirs[target] |= access
+				delete(dirs, dir)
+			}
+		}
+	}
+
+	for dir, access := range dirs {
+		for p := filepath.Dir(dir); ; p = filepath.Dir(p) {
+			if a, ok := dirs[p]; ok && access&^a == 0 {
+				delete(dirs, dir)
+				break
+			}
+			if p == filepath.Dir(p) {
+				break
+			}
+		}
+	}
+}
+
+func pathDepth(p string) int {
+	p = strings.Trim(filepath.Clean(p), "/")
+	if p == "" {
+		return 0
+	}
+	return strings.Count(p, "/") + 1
+}
+
+func sortedPorts(ports map[uint16]bool) []uint16 {
+	var res []uint16
+	for p := range ports {
+		res = append(res, p)
+	}
+	slices.Sort(res)
+	return res
+}
+
+// Rules returns the suggested rules.
+func (s *Suggestion) Rules() []landlock.Rule {
+	var rules []landlock.Rule
+	for _, fs := range s.FS {
+		rules = append(rules, landlock.PathAccess(fs.Access, fs.Paths...))
+	}
+	for _, p := range s.BindTCP {
+		rules = append(rules, landlock.BindTCP(p))
+	}
+	for _, p := range s.ConnectTCP {
+		rules = append(rules, landlock.ConnectTCP(p))
+	}
+	return rules
+}
+
+// GoSource returns the suggested policy as a Go code snippet, which
+// uses the rule constructors of the landlock package.
+func (s *Suggestion) GoSource() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "// Suggested from %d observed Landlock denials.\n", s.Denials)
+	if s.Scoped != 0 {
+		fmt.Fprintf(&b, "// NOTE: Denials in IPC scopes %v can not be permitted with rules.\n", s.Scoped)
+	}
+	if len(s.Unresolved) > 0 {
+		fmt.Fprintf(&b, "// NOTE: Denials for %v can not be permitted with rules.\n", strings.Join(s.Unresolved, ","))
+	}
+	b.WriteString("err := landlock.V9.BestEffort().Restrict(\n")
+	for _, fs := range s.FS {
+		fmt.Fprintf(&b, "\t%s,\n", fsConstructor(fs.Access, fs.Paths))
+	}
+	for _, p := range s.BindTCP {
+		fmt.Fprintf(&b, "\tlandlock.BindTCP(%d),\n", p)
+	}
+	for _, p := range s.ConnectTCP {
+		fmt.Fprintf(&b, "\tlandlock.ConnectTCP(%d),\n", p)
+	}
+	b.WriteString(")\n")
+	return b.String()
+}
+
+// fsConstructor returns the Go expression for a rule constructor call
+// which grants exactly the access rights a to paths.
+func fsConstructor(a landlock.AccessFSSet, paths []string) string {
+	var quoted []string
+	for _, p := range paths {
+		quoted = append(quoted, fmt.Sprintf("%q", p))
+	}
+	args := strings.Join(quoted, ", ")
+
+	if read, err := landlock.ParseAccessFSSet("read"); err == nil && a == read {
+		return "landlock.RODirs(" + args + ")"
+	}
+	if rw, err := landlock.ParseAccessFSSet("read_write"); err == nil && a == rw {
+		return "landlock.RWDirs(" + args + ")"
+	}
+	var consts []string
+	for _, name := range accessNames(a.String()) {
+		consts = append(consts, "ll.AccessFS"+camelCase(name))
+	}
+	return "landlock.PathAccess(" + strings.Join(consts, "|") + ", " + args + ")"
+}
+
+// accessNames splits the String() representation of an access set
+// like "{read_file,read_dir}" into its names.
+func accessNames(s string) []string {
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "{"), "}")
+	if s == "" || s == "∅" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+func camelCase(name string) string {
+	var b strings.Builder
+	for _, word := range strings.Split(name, "_") {
+		if word == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return b.String()
+}
+
+// policyJSON mirrors the policy file format read by
+// [landlock.LoadPolicy].
+type policyJSON struct {
+	Version          int         `json:"version"`
+	HandledAccessFS  []string    `json:"handled_access_fs"`
+	HandledAccessNet []string    `json:"handled_access_net"`
+	Scoped           []string    `json:"scoped"`
+	BestEffort       bool        `json:"best_effort"`
+	FS               []policyFS  `json:"fs,omitempty"`
+	Net              []policyNet `json:"net,omitempty"`
+}
+
+type policyFS struct {
+	Rule   string   `json:"rule"`
+	Paths  []string `json:"paths"`
+	Access []string `json:"access"`
+}
+
+type policyNet struct {
+	Rule  string   `json:"rule"`
+	Ports []uint16 `json:"ports"`
+}
+
+// PolicyJSON returns the suggested policy in the policy file format
+// which is read by [landlock.LoadPolicy].
+//
+// The policy handles all access rights and all IPC scopes except for
+// the ones listed in [Suggestion.Scoped].
+func (s *Suggestion) PolicyJSON() ([]byte, error) {
+	allScoped, err := landlock.ParseScopedSet("all")
+	if err != nil {
+		return nil, err
+	}
+	p := policyJSON{
+		Version:          1,
+		HandledAccessFS:  []string{"all"},
+		HandledAccessNet: []string{"all"},
+		Scoped:           accessNames((allScoped &^ s.Scoped).String()),
+		BestEffort:       true,
+	}
+	if p.Scoped == nil {
+		p.Scoped = []string{}
+	}
+	for _, fs := range s.FS {
+		p.FS = append(p.FS, policyFS{
+			Rule:   "path_access",
+			Paths:  fs.Paths,
+			Access: accessNames(fs.Access.String()),
+		})
+	}
+	if len(s.BindTCP) > 0 {
+		p.Net = append(p.Net, policyNet{Rule: "bind_tcp", Ports: s.BindTCP})
+	}
+	if len(s.ConnectTCP) > 0 {
+		p.Net = append(p.Net, policyNet{Rule: "connect_tcp", Ports: s.ConnectTCP})
+	}
+	return json.MarshalIndent(p, "", "  ")
+}
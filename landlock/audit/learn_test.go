@@ -0,0 +1,140 @@
+package audit
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/landlock-lsm/go-landlock/landlock"
+	ll "github.com/landlock-lsm/go-landlock/landlock/syscall"
+)
+
+func fsDenial(domain uint64, access landlock.AccessFSSet, path string) *Access {
+	return &Access{Domain: domain, AccessFS: access, Path: path}
+}
+
+func TestLearnerSuggest(t *testing.T) {
+	l := &Learner{}
+	for _, rec := range []Record{
+		fsDenial(1, ll.AccessFSReadFile, "/usr/lib/x86_64/libc.so"),
+		fsDenial(1, ll.AccessFSReadFile, "/usr/lib/python3/os.py"),
+		fsDenial(1, ll.AccessFSReadFile, "/usr/lib/locale/C/LC_CTYPE"),
+		fsDenial(1, ll.AccessFSReadFile|ll.AccessFSExecute, "/usr/bin/ls"),
+		fsDenial(1, ll.AccessFSReadFile, "/etc/passwd"),
+		fsDenial(1, ll.AccessFSReadFile, "/etc/ssl/certs.pem"), // Covered by /etc.
+		fsDenial(1, ll.AccessFSMakeReg|ll.AccessFSRemoveFile, "/tmp"),
+		fsDenial(1, ll.AccessFSWriteFile, "/tmp/out"),
+		&Access{Domain: 1, AccessNet: ll.AccessNetConnectTCP, Port: 443},
+		&Access{Domain: 1, AccessNet: ll.AccessNetConnectTCP, Port: 80},
+		&Access{Domain: 1, AccessNet: ll.AccessNetConnectTCP, Port: 443},
+		&Access{Domain: 1, AccessNet: ll.AccessNetBindTCP, Port: 8080},
+		&Access{Domain: 1, Blockers: []string{"scope.signal"}, Scoped: ll.ScopeSignal},
+		&Access{Domain: 1, Blockers: []string{"fs.change_topology"}},
+	} {
+		l.Add(rec)
+	}
+
+	got := l.Suggest()
+	want := &Suggestion{
+		FS: []FSSuggestion{
+			{Paths: []string{"/etc", "/usr/lib"}, Access: ll.AccessFSReadFile},
+			{Paths: []string{"/tmp"}, Access: ll.AccessFSWriteFile | ll.AccessFSMakeReg | ll.AccessFSRemoveFile},
+			{Paths: []string{"/usr/bin"}, Access: ll.AccessFSReadFile | ll.AccessFSExecute},
+		},
+		BindTCP:    []uint16{8080},
+		ConnectTCP: []uint16{80, 443},
+		Scoped:     ll.ScopeSignal,
+		Unresolved: []string{"fs.change_topology"},
+		Denials:    14,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Suggest() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	wantSrc := `// Suggested from 14 observed Landlock denials.
+// NOTE: Denials in IPC scopes {signal} can not be permitted with rules.
+// NOTE: Denials for fs.change_topology can not be permitted with rules.
+err := landlock.V9.BestEffort().Restrict(
+	landlock.PathAccess(ll.AccessFSReadFile, "/etc", "/usr/lib"),
+	landlock.PathAccess(ll.AccessFSWriteFile|ll.AccessFSRemoveFile|ll.AccessFSMakeReg, "/tmp"),
+	landlock.PathAccess(ll.AccessFSExecute|ll.AccessFSReadFile, "/usr/bin"),
+	landlock.BindTCP(8080),
+	landlock.ConnectTCP(80),
+	landlock.ConnectTCP(443),
+)
+`
+	if src := got.GoSource(); src != wantSrc {
+		t.Errorf("GoSource() =\n%s\nwant\n%s", src, wantSrc)
+	}
+}
+
+func TestLearnerPolicyJSON(t *testing.T) {
+	s := &Suggestion{
+		FS: []FSSuggestion{
+			{Paths: []string{"/usr"}, Access: ll.AccessFSReadFile | ll.AccessFSReadDir},
+		},
+		ConnectTCP: []uint16{53},
+		Scoped:     ll.ScopeSignal,
+	}
+	data, err := s.PolicyJSON()
+	if err != nil {
+		t.Fatalf("PolicyJSON: %v", err)
+	}
+	cfg, rules, err := landlock.LoadPolicy(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("LoadPolicy(%s): %v", data, err)
+	}
+	wantCfg := landlock.MustConfig(
+		landlock.AccessFSSet((1<<17)-1),
+		landlock.AccessNetSet(ll.AccessNetBindTCP|ll.AccessNetConnectTCP),
+		landlock.ScopedSet(ll.ScopeAbstractUnixSocket),
+	).BestEffort()
+	if cfg != wantCfg {
+		t.Errorf("LoadPolicy config = %v, want %v", cfg, wantCfg)
+	}
+	if got, want := len(rules), len(s.Rules()); got != want {
+		t.Errorf("LoadPolicy returned %d rules, want %d", got, want)
+	}
+}
+
+func TestLearnerTrackPID(t *testing.T) {
+	f, err := os.Open("testdata/audit.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	recs, err := NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		pid         int
+		wantDenials int
+	}{
+		{pid: 286, wantDenials: 5},
+		{pid: 1, wantDenials: 0},
+	} {
+		l := &Learner{}
+		l.TrackPID(tt.pid)
+		for _, rec := range recs {
+			l.Add(rec)
+		}
+		if got := l.Suggest().Denials; got != tt.wantDenials {
+			t.Errorf("TrackPID(%d): Denials = %d, want %d", tt.pid, got, tt.wantDenials)
+		}
+	}
+}
+
+func TestGoSourceRODirs(t *testing.T) {
+	read, err := landlock.ParseAccessFSSet("read")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Suggestion{FS: []FSSuggestion{{Paths: []string{"/usr"}, Access: read}}}
+	if src := s.GoSource(); !strings.Contains(src, `landlock.RODirs("/usr"),`) {
+		t.Errorf("GoSource() = %s, want RODirs", src)
+	}
+}
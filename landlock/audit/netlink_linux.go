@@ -0,0 +1,102 @@
+//go:build linux
+
+package audit
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// auditNetlinkGroupReadlog is AUDIT_NLGRP_READLOG, the netlink
+// multicast group on which the kernel publishes audit records.
+const auditNetlinkGroupReadlog = 1
+
+// DialNetlink subscribes to the kernel's audit netlink multicast group
+// and returns a Reader for the Landlock audit records which the
+// kernel emits from then on.
+//
+// This requires the CAP_AUDIT_READ capability.  It works alongside a
+// running audit daemon.  The returned Reader should be closed after
+// use; closing it unblocks a concurrent call to [Reader.Read].
+func DialNetlink() (*Reader, error) {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_RAW|unix.SOCK_CLOEXEC|unix.SOCK_NONBLOCK, unix.NETLINK_AUDIT)
+	if err != nil {
+		return nil, fmt.Errorf("audit netlink socket: %w", err)
+	}
+	sa := &unix.SockaddrNetlink{
+		Family: unix.AF_NETLINK,
+		Groups: 1 << (auditNetlinkGroupReadlog - 1),
+	}
+	if err := unix.Bind(fd, sa); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("audit netlink bind: %w", err)
+	}
+	// Wrapping the non-blocking socket in an os.File registers it
+	// with the runtime poller, so that Close interrupts reads.
+	f := os.NewFile(uintptr(fd), "audit-netlink")
+	rc, err := f.SyscallConn()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("audit netlink socket: %w", err)
+	}
+	s := &netlinkSource{f: f, rc: rc, buf: make([]byte, 64*1024)}
+	return &Reader{next: s.next, closer: s}, nil
+}
+
+type netlinkSource struct {
+	f       *os.File
+	rc      syscall.RawConn
+	buf     []byte
+	pending []syscall.NetlinkMessage
+	closed  atomic.Bool
+}
+
+func (s *netlinkSource) next() (message, error) {
+	for {
+		for len(s.pending) > 0 {
+			nm := s.pending[0]
+			s.pending = s.pending[1:]
+			text := strings.TrimRight(string(nm.Data), "\x00\n")
+			if m, ok := parseMessage(int(nm.Header.Type), text); ok {
+				return m, nil
+			}
+		}
+		var n int
+		var rerr error
+		err := s.rc.Read(func(fd uintptr) bool {
+			n, _, rerr = unix.Recvfrom(int(fd), s.buf, 0)
+			return rerr != unix.EAGAIN
+		})
+		if s.closed.Load() || errors.Is(err, os.ErrClosed) {
+			return message{}, io.EOF
+		}
+		if err == nil {
+			err = rerr
+		}
+		if err == unix.EINTR || err == unix.ENOBUFS {
+			// ENOBUFS: Records were dropped because the
+			// receive buffer overflowed; keep reading.
+			continue
+		}
+		if err != nil {
+			return message{}, fmt.Errorf("audit netlink receive: %w", err)
+		}
+		msgs, err := syscall.ParseNetlinkMessage(s.buf[:n])
+		if err != nil {
+			return message{}, fmt.Errorf("audit netlink message: %w", err)
+		}
+		s.pending = msgs
+	}
+}
+
+func (s *netlinkSource) Close() error {
+	s.closed.Store(true)
+	return s.f.Close()
+}
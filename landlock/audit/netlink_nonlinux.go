@@ -0,0 +1,11 @@
+//go:build !linux
+
+package audit
+
+import "errors"
+
+// DialNetlink subscribes to the kernel's audit netlink multicast group.
+// It is only supported on Linux.
+func DialNetlink() (*Reader, error) {
+	return nil, errors.New("audit netlink socket: only supported on Linux")
+}
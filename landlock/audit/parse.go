@@ -0,0 +1,221 @@
+package audit
+
+import (
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/landlock-lsm/go-landlock/landlock"
+)
+
+// typeNames maps the record type names used by auditd to record types.
+var typeNames = map[string]int{
+	"SYSCALL":         TypeSyscall,
+	"EOE":             TypeEOE,
+	"LANDLOCK_ACCESS": TypeLandlockAccess,
+	"LANDLOCK_DOMAIN": TypeLandlockDomain,
+}
+
+// parseType parses a record type, which is either a name like
+// "LANDLOCK_ACCESS", a number, or "UNKNOWN[1423]" as written by
+// auditd versions which do not know the record type.
+func parseType(s string) (int, bool) {
+	if t, ok := typeNames[s]; ok {
+		return t, true
+	}
+	if rest, ok := strings.CutPrefix(s, "UNKNOWN["); ok {
+		s, ok = strings.CutSuffix(rest, "]")
+		if !ok {
+			return 0, false
+		}
+	}
+	t, err := strconv.Atoi(s)
+	return t, err == nil
+}
+
+// parseLine parses a single line of audit log output.  The lines look
+// like one of
+//
+//	type=LANDLOCK_ACCESS msg=audit(1729738800.268:30): domain=... (auditd)
+//	audit: type=1423 audit(1729738800.268:30): domain=...         (kernel log)
+//
+// ok is false if the line is not an audit record.
+func parseLine(line string) (m message, ok bool) {
+	i := strings.Index(line, "type=")
+	if i < 0 {
+		return message{}, false
+	}
+	line = line[i+len("type="):]
+	typ, rest, _ := strings.Cut(line, " ")
+	m.typ, ok = parseType(typ)
+	if !ok {
+		return message{}, false
+	}
+	i = strings.Index(rest, "audit(")
+	if i < 0 {
+		return message{}, false
+	}
+	return parseMessage(m.typ, rest[i:])
+}
+
+// parseMessage parses an audit message of the given type.  The
+// message text starts with "audit(<time>:<serial>): ".
+func parseMessage(typ int, text string) (m message, ok bool) {
+	text, ok = strings.CutPrefix(text, "audit(")
+	if !ok {
+		return message{}, false
+	}
+	stamp, body, ok := strings.Cut(text, "):")
+	if !ok {
+		return message{}, false
+	}
+	ts, serial, ok := strings.Cut(stamp, ":")
+	if !ok {
+		return message{}, false
+	}
+	m.typ = typ
+	m.hdr.Serial, ok = parseUint(serial, 10)
+	if !ok {
+		return message{}, false
+	}
+	m.hdr.Time, ok = parseTime(ts)
+	if !ok {
+		return message{}, false
+	}
+	// In the "enriched" log format, auditd appends interpreted
+	// fields after a 0x1d separator.
+	body, _, _ = strings.Cut(body, "\x1d")
+	m.body = strings.TrimSpace(body)
+	m.hdr.Fields = parseFields(m.body)
+	return m, true
+}
+
+// parseTime parses an audit timestamp like "1729738800.268".
+func parseTime(s string) (time.Time, bool) {
+	sec, frac, _ := strings.Cut(s, ".")
+	secs, ok := parseUint(sec, 10)
+	if !ok {
+		return time.Time{}, false
+	}
+	var nsecs uint64
+	if frac != "" {
+		if len(frac) > 9 {
+			frac = frac[:9]
+		}
+		nsecs, ok = parseUint(frac+strings.Repeat("0", 9-len(frac)), 10)
+		if !ok {
+			return time.Time{}, false
+		}
+	}
+	return time.Unix(int64(secs), int64(nsecs)), true
+}
+
+// untrustedFields are the fields which the kernel logs with
+// audit_log_untrustedstring().  Their values are either quoted, or
+// hex-encoded if they contain special characters.
+var untrustedFields = map[string]bool{
+	"path":  true,
+	"comm":  true,
+	"exe":   true,
+	"ocomm": true,
+	"name":  true,
+}
+
+// parseFields splits an audit record body into its key=value pairs.
+func parseFields(body string) map[string]string {
+	fields := make(map[string]string)
+	for body != "" {
+		body = strings.TrimLeft(body, " ")
+		key, rest, ok := strings.Cut(body, "=")
+		if !ok || strings.Contains(key, " ") {
+			// Not a key=value pair; skip the word.
+			_, body, _ = strings.Cut(body, " ")
+			continue
+		}
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				value, body = rest[1:], ""
+			} else {
+				value, body = rest[1:end+1], rest[end+2:]
+			}
+		} else {
+			value, body, _ = strings.Cut(rest, " ")
+			if untrustedFields[key] {
+				if dec, err := hex.DecodeString(value); err == nil {
+					value = string(dec)
+				}
+			}
+		}
+		fields[key] = value
+	}
+	return fields
+}
+
+func parseUint(s string, base int) (uint64, bool) {
+	n, err := strconv.ParseUint(s, base, 64)
+	return n, err == nil
+}
+
+func decodeAccess(m message) *Access {
+	f := m.hdr.Fields
+	a := &Access{Header: m.hdr}
+	a.Domain, _ = parseUint(f["domain"], 16)
+	if f["blockers"] != "" {
+		a.Blockers = strings.Split(f["blockers"], ",")
+	}
+	for _, b := range a.Blockers {
+		kind, name, _ := strings.Cut(b, ".")
+		switch kind {
+		case "fs":
+			if s, err := landlock.ParseAccessFSSet(name); err == nil {
+				a.AccessFS |= s
+			}
+		case "net":
+			if s, err := landlock.ParseAccessNetSet(name); err == nil {
+				a.AccessNet |= s
+			}
+		case "scope":
+			if s, err := landlock.ParseScopedSet(name); err == nil {
+				a.Scoped |= s
+			}
+		}
+	}
+	a.Path = f["path"]
+	a.Dev = f["dev"]
+	a.Ino, _ = parseUint(f["ino"], 10)
+	port := f["dest"]
+	a.Addr = f["daddr"]
+	if port == "" {
+		port = f["src"]
+		a.Addr = f["saddr"]
+	}
+	if p, ok := parseUint(port, 10); ok && p <= 0xffff {
+		a.Port = uint16(p)
+	}
+	return a
+}
+
+// addProcess fills in the process information from the fields of a
+// SYSCALL record.
+func (a *Access) addProcess(f map[string]string) {
+	a.PID, _ = strconv.Atoi(f["pid"])
+	a.Comm = f["comm"]
+	a.Exe = f["exe"]
+}
+
+func decodeDomain(m message) *Domain {
+	f := m.hdr.Fields
+	d := &Domain{Header: m.hdr}
+	d.Domain, _ = parseUint(f["domain"], 16)
+	d.Status = f["status"]
+	d.Mode = f["mode"]
+	d.PID, _ = strconv.Atoi(f["pid"])
+	d.UID, _ = strconv.Atoi(f["uid"])
+	d.Exe = f["exe"]
+	d.Comm = f["comm"]
+	d.Denials, _ = parseUint(f["denials"], 10)
+	return d
+}
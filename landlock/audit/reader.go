@@ -0,0 +1,138 @@
+package audit
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+// A Reader decodes Landlock audit records from a stream of audit
+// messages.
+//
+// Records which belong to the same audit event are buffered until the
+// event is complete, so that information from the accompanying
+// SYSCALL record can be attached to [Access] records.  Other record
+// types are skipped.
+type Reader struct {
+	next   func() (message, error)
+	closer io.Closer
+
+	event   []message // Buffered messages of the current event.
+	pending []Record  // Decoded records which have not been returned yet.
+	err     error     // Sticky error from next.
+}
+
+// message is a single undecoded audit record.
+type message struct {
+	typ  int
+	hdr  Header
+	body string
+}
+
+// NewReader returns a Reader which reads audit records from r, one
+// record per line.
+//
+// The following line formats are understood:
+//
+//   - Audit log files as written by auditd(8), in the "raw" or
+//     "enriched" log format, e.g. from /var/log/audit/audit.log.
+//   - Kernel log messages, as printed by dmesg(1) or journalctl(1)
+//     when no audit daemon is running.
+//
+// Lines which are not audit records are ignored.
+func NewReader(r io.Reader) *Reader {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1024*1024)
+	return &Reader{
+		next: func() (message, error) {
+			for sc.Scan() {
+				m, ok := parseLine(sc.Text())
+				if ok {
+					return m, nil
+				}
+			}
+			if err := sc.Err(); err != nil {
+				return message{}, err
+			}
+			return message{}, io.EOF
+		},
+	}
+}
+
+// Read returns the next Landlock audit record.
+// At the end of the input, it returns [io.EOF].
+func (r *Reader) Read() (Record, error) {
+	for len(r.pending) == 0 {
+		if r.err != nil {
+			if len(r.event) > 0 {
+				r.flush()
+				continue
+			}
+			return nil, r.err
+		}
+		m, err := r.next()
+		if err != nil {
+			r.err = err
+			continue
+		}
+		if len(r.event) > 0 && r.event[0].hdr.Serial != m.hdr.Serial {
+			r.flush()
+		}
+		if m.typ == TypeEOE {
+			r.flush()
+			continue
+		}
+		r.event = append(r.event, m)
+	}
+	rec := r.pending[0]
+	r.pending = r.pending[1:]
+	return rec, nil
+}
+
+// ReadAll reads all remaining Landlock audit records until the end of
+// the input.
+func (r *Reader) ReadAll() ([]Record, error) {
+	var recs []Record
+	for {
+		rec, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return recs, nil
+		}
+		if err != nil {
+			return recs, err
+		}
+		recs = append(recs, rec)
+	}
+}
+
+// Close closes the underlying audit socket, if the reader was created
+// with [DialNetlink].  For other readers, it does nothing.
+func (r *Reader) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+	return r.closer.Close()
+}
+
+// flush decodes the buffered messages of the current event.
+func (r *Reader) flush() {
+	var syscall *message
+	for i, m := range r.event {
+		if m.typ == TypeSyscall {
+			syscall = &r.event[i]
+		}
+	}
+	for _, m := range r.event {
+		switch m.typ {
+		case TypeLandlockAccess:
+			a := decodeAccess(m)
+			if syscall != nil {
+				a.addProcess(syscall.hdr.Fields)
+			}
+			r.pending = append(r.pending, a)
+		case TypeLandlockDomain:
+			r.pending = append(r.pending, decodeDomain(m))
+		}
+	}
+	r.event = nil
+}
@@ -1,4 +1,4 @@
-//go:build linux && (!cgo || landlocktsync)
+//go:build !linux || !cgo || landlocktsync
 
 package landlock
 
@@ -0,0 +1,242 @@
+package landlock
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	ll "github.com/landlock-lsm/go-landlock/landlock/syscall"
+)
+
+// accessFSDirContent is the set of access rights which modify the
+// contents of a directory.  They are checked on the directory which
+// contains the file being created, removed, linked or renamed.
+const accessFSDirContent AccessFSSet = ll.AccessFSRemoveDir | ll.AccessFSRemoveFile | ll.AccessFSMakeChar | ll.AccessFSMakeDir | ll.AccessFSMakeReg | ll.AccessFSMakeSock | ll.AccessFSMakeFifo | ll.AccessFSMakeBlock | ll.AccessFSMakeSym | ll.AccessFSRefer
+
+// accessFSNonDir is the set of access rights which are relevant for
+// files which are not directories.
+const accessFSNonDir AccessFSSet = accessFile | ll.AccessFSIoctlDev | ll.AccessFSResolveUnix
+
+// Check reports whether the policy permits the filesystem access
+// rights in access on path, without enforcing anything.  It returns
+// nil if the access is permitted, and an [*AccessDeniedError]
+// otherwise.
+//
+// Check models Landlock's evaluation of a single ruleset:
+//
+//   - Access rights which are not handled by the Config are always
+//     permitted, with the exception of "refer", which is denied by any
+//     ruleset which handles filesystem access.
+//   - Access rights which are granted on a directory are granted on
+//     all files beneath it.
+//   - Access rights which modify the contents of a directory (the
+//     "make_*", "remove_*" and "refer" rights) are checked on the
+//     directory containing path.  For example, creating the regular
+//     file "/var/log/app/x.log" requires "make_reg" on
+//     "/var/log/app".  All other access rights are checked on path
+//     itself.
+//
+// Paths are compared lexically, so they should be absolute and the
+// rules should not rely on symlinks.  Check evaluates the policy as
+// given, without the downgrades of best effort mode; use
+// [Config.Explain] to find out which Config is effective on the
+// running kernel.
+//
+// Dynamic rules are resolved with the policy's Config, and the paths
+// of rules which use [FSRule.Expand] are expanded.  Check returns an
+// [*IncompatibleRuleError] if a rule is incompatible with the Config,
+// and an error if a path can not be expanded, as [Config.Restrict]
+// would.  Like the kernel, Check refuses rules which grant access
+// rights that only apply to directories (such as "read_dir" or the
+// "make_*" rights) on existing files which are not directories; it
+// returns a [*PathRuleError] matching [syscall.EINVAL] for them.
+// Paths which do not exist are not checked.
+func (p Policy) Check(access AccessFSSet, path string) error {
+	rules, err := p.checkedRules()
+	if err != nil {
+		return err
+	}
+	path = filepath.Clean(path)
+	if err := p.checkFS(rules, access&accessFSDirContent, filepath.Dir(path)); err != nil {
+		return err
+	}
+	return p.checkFS(rules, access&^accessFSDirContent, path)
+}
+
+// CheckNet reports whether the policy permits the network access
+// rights in access on the given TCP port, without enforcing
+// anything.  It returns nil if the access is permitted, and an
+// [*AccessDeniedError] otherwise.
+func (p Policy) CheckNet(access AccessNetSet, port uint16) error {
+	rules, err := p.checkedRules()
+	if err != nil {
+		return err
+	}
+	granted := grantedNet(rules, p.Config, port)
+	if d := access.intersect(p.Config.handledAccessNet) &^ granted; !d.isEmpty() {
+		return &AccessDeniedError{Port: port, AccessNet: d, Errno: syscall.EACCES}
+	}
+	return nil
+}
+
+// CheckRename reports whether the policy permits renaming the file at
+// src to dst, which must not exist yet.  isDir specifies whether src
+// is a directory; other files are assumed to be regular files.
+//
+// Renaming a file within the same directory requires the rights to
+// remove and to create it.  Renaming it to a different directory
+// requires the "refer" right on both directories in addition, and the
+// file must not gain any access rights by moving it, i.e. the
+// destination directory may not grant any handled access rights
+// which the source directory does not grant.  Like the kernel,
+// CheckRename returns an error matching [syscall.EXDEV] if only these
+// additional requirements are not met.
+func (p Policy) CheckRename(src, dst string, isDir bool) error {
+	remove, create := AccessFSSet(ll.AccessFSRemoveFile), AccessFSSet(ll.AccessFSMakeReg)
+	if isDir {
+		remove, create = ll.AccessFSRemoveDir, ll.AccessFSMakeDir
+	}
+	if err := p.Check(remove, src); err != nil {
+		return err
+	}
+	if err := p.Check(create, dst); err != nil {
+		return err
+	}
+	return p.checkRefer(src, dst, isDir)
+}
+
+// CheckLink reports whether the policy permits creating a hard link
+// at dst to the regular file at src.
+//
+// Linking requires the right to create a regular file in the
+// directory of dst.  Linking to a different directory has the same
+// additional requirements as for [Policy.CheckRename].
+func (p Policy) CheckLink(src, dst string) error {
+	if err := p.Check(ll.AccessFSMakeReg, dst); err != nil {
+		return err
+	}
+	return p.checkRefer(src, dst, false)
+}
+
+// checkRefer checks the requirements for linking or renaming the file
+// at src to dst, if they are in different directories.
+func (p Policy) checkRefer(src, dst string, isDir bool) error {
+	srcDir, dstDir := filepath.Dir(filepath.Clean(src)), filepath.Dir(filepath.Clean(dst))
+	if srcDir == dstDir {
+		return nil
+	}
+	rules, err := p.checkedRules()
+	if err != nil {
+		return err
+	}
+	for _, dir := range []string{srcDir, dstDir} {
+		if d := ll.AccessFSRefer &^ p.permittedFS(rules, dir); d != 0 {
+			return &AccessDeniedError{Path: dir, AccessFS: d, Errno: syscall.EXDEV}
+		}
+	}
+	relevant := p.Config.handledAccessFS
+	if !isDir {
+		relevant = relevant.intersect(accessFSNonDir)
+	}
+	if d := (p.permittedFS(rules, dstDir) &^ p.permittedFS(rules, srcDir)).intersect(relevant); !d.isEmpty() {
+		return &AccessDeniedError{
+			Path:     dstDir,
+			AccessFS: d,
+			Reason:   "not granted on " + srcDir,
+			Errno:    syscall.EXDEV,
+		}
+	}
+	return nil
+}
+
+func (p Policy) checkFS(rules []Rule, access AccessFSSet, path string) error {
+	if d := access &^ p.permittedFS(rules, path); !d.isEmpty() {
+		errno := syscall.EACCES
+		if hasRefer(d) {
+			errno = syscall.EXDEV
+		}
+		return &AccessDeniedError{Path: path, AccessFS: d, Errno: errno}
+	}
+	return nil
+}
+
+// permittedFS returns the filesystem access rights which the policy
+// with the given resolved rules permits on path.
+func (p Policy) permittedFS(rules []Rule, path string) AccessFSSet {
+	handled := p.Config.handledAccessFS
+	if handled.isEmpty() {
+		return ^AccessFSSet(0)
+	}
+	unhandled := ^handled &^ ll.AccessFSRefer
+	return grantedFS(rules, p.Config, path) | unhandled
+}
+
+// checkedRules resolves the rules of the policy and checks that they
+// are compatible with its Config, as [Config.Restrict] would.
+func (p Policy) checkedRules() ([]Rule, error) {
+	rules, err := resolveDynamicRules(p.Config, p.Rules)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		if !rule.compatibleWithConfig(p.Config) {
+			return nil, &IncompatibleRuleError{Rule: rule}
+		}
+	}
+	rules, err = expandRules(flattenRules(rules))
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		if r, ok := rule.(FSRule); ok {
+			if err := r.checkDirAccess(p.Config); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return rules, nil
+}
+
+// checkDirAccess returns an error if the rule grants access rights
+// which only apply to directories on an existing file which is not a
+// directory.  The kernel refuses to add such rules with EINVAL.
+func (r FSRule) checkDirAccess(c Config) error {
+	access := r.effectiveAccess(c)
+	dirOnly := access &^ accessFSNonDir
+	if dirOnly.isEmpty() {
+		return nil
+	}
+	check := func(name string, fi fs.FileInfo, err error) error {
+		if err != nil || fi.IsDir() {
+			return nil
+		}
+		return newPathRuleError(name, access, fmt.Errorf("directory access rights %v on a file which is not a directory: %w", dirOnly, syscall.EINVAL))
+	}
+	for _, path := range r.paths {
+		fi, err := r.stat(path)
+		if err := check(path, fi, err); err != nil {
+			return err
+		}
+	}
+	for _, f := range r.files {
+		fi, err := f.Stat()
+		if err := check(f.Name(), fi, err); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stat returns the file info for path, resolving it like the rule
+// does when it is added to a ruleset.
+func (r FSRule) stat(path string) (fs.FileInfo, error) {
+	switch {
+	case r.root != nil:
+		return fs.Stat(r.rootFS(), filepath.ToSlash(filepath.Clean(path)))
+	case r.noFollow:
+		return os.Lstat(path)
+	}
+	return os.Stat(path)
+}
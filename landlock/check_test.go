@@ -0,0 +1,193 @@
+package landlock
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	ll "github.com/landlock-lsm/go-landlock/landlock/syscall"
+)
+
+func TestPolicyCheck(t *testing.T) {
+	p := Policy{
+		Config: V5,
+		Rules: []Rule{
+			RODirs("/usr"),
+			RWDirs("/var/log/app"),
+			ROFiles("/etc/hosts"),
+			PathAccess(ll.AccessFSMakeReg, "/srv/upload"),
+		},
+	}
+	for _, tt := range []struct {
+		name   string
+		access AccessFSSet
+		path   string
+		want   syscall.Errno // 0 if permitted
+	}{
+		{"ReadBeneath", ll.AccessFSReadFile, "/usr/lib/libc.so", 0},
+		{"ReadDir", ll.AccessFSReadDir, "/usr", 0},
+		{"WriteOutside", ll.AccessFSWriteFile, "/usr/lib/libc.so", syscall.EACCES},
+		{"WriteLog", ll.AccessFSWriteFile | ll.AccessFSTruncate, "/var/log/app/x.log", 0},
+		{"CreateLog", ll.AccessFSMakeReg, "/var/log/app/x.log", 0},
+		{"CreateLogDir", ll.AccessFSMakeDir, "/var/log/app", syscall.EACCES},
+		{"CreateUpload", ll.AccessFSMakeReg, "/srv/upload/f", 0},
+		{"ReadUpload", ll.AccessFSReadFile, "/srv/upload/f", syscall.EACCES},
+		{"SimilarPrefix", ll.AccessFSReadFile, "/usr2/f", syscall.EACCES},
+		{"File", ll.AccessFSReadFile, "/etc/hosts", 0},
+		{"Refer", ll.AccessFSRefer, "/var/log/app/x.log", syscall.EXDEV},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := p.Check(tt.access, tt.path)
+			if tt.want == 0 {
+				if err != nil {
+					t.Errorf("Check(%v, %q) = %v, want nil", tt.access, tt.path, err)
+				}
+				return
+			}
+			var ade *AccessDeniedError
+			if !errors.As(err, &ade) || !errors.Is(err, tt.want) {
+				t.Errorf("Check(%v, %q) = %v, want AccessDeniedError matching %v", tt.access, tt.path, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyCheckUnhandled(t *testing.T) {
+	p := Policy{Config: V1, Rules: []Rule{RODirs("/usr")}}
+	// Truncation is not handled by V1.
+	if err := p.Check(ll.AccessFSTruncate, "/tmp/f"); err != nil {
+		t.Errorf("Check(truncate) = %v, want nil", err)
+	}
+	// Refer is denied by any ruleset which handles filesystem access.
+	if err := p.Check(ll.AccessFSRefer, "/usr/f"); !errors.Is(err, syscall.EXDEV) {
+		t.Errorf("Check(refer) = %v, want EXDEV", err)
+	}
+
+	p = Policy{Config: MustConfig(AccessNetSet(ll.AccessNetConnectTCP))}
+	if err := p.Check(ll.AccessFSRefer|ll.AccessFSWriteFile, "/tmp/f"); err != nil {
+		t.Errorf("Check() without handled FS rights = %v, want nil", err)
+	}
+}
+
+func TestPolicyCheckIncompatible(t *testing.T) {
+	p := Policy{Config: V1, Rules: []Rule{ConnectTCP(53)}}
+	var ire *IncompatibleRuleError
+	if err := p.Check(ll.AccessFSReadFile, "/"); !errors.As(err, &ire) {
+		t.Errorf("Check() = %v, want IncompatibleRuleError", err)
+	}
+}
+
+func TestPolicyCheckDirAccessOnFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "f")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		name    string
+		rule    Rule
+		wantErr bool
+	}{
+		{"DirRightsOnFile", PathAccess(ll.AccessFSReadDir|ll.AccessFSMakeReg, file), true},
+		{"RODirsOnFile", RODirs(file), true},
+		{"ROFilesOnFile", ROFiles(file), false},
+		{"DirRightsOnDir", RWDirs(dir), false},
+		{"Missing", RODirs(filepath.Join(dir, "missing")), false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Policy{Config: V5, Rules: []Rule{tt.rule}}
+			err := p.Check(ll.AccessFSReadFile, file)
+			var pre *PathRuleError
+			if got := errors.As(err, &pre) && errors.Is(err, syscall.EINVAL); got != tt.wantErr {
+				t.Errorf("Check() = %v, want PathRuleError matching EINVAL: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPolicyCheckNet(t *testing.T) {
+	p := Policy{Config: V4, Rules: []Rule{ConnectTCP(5432), BindTCPRange(8000, 8010)}}
+	if err := p.CheckNet(ll.AccessNetConnectTCP, 5432); err != nil {
+		t.Errorf("CheckNet(connect, 5432) = %v, want nil", err)
+	}
+	if err := p.CheckNet(ll.AccessNetBindTCP, 8005); err != nil {
+		t.Errorf("CheckNet(bind, 8005) = %v, want nil", err)
+	}
+	err := p.CheckNet(ll.AccessNetConnectTCP, 8005)
+	if want := "access {connect_tcp} denied on TCP port 8005"; err == nil || err.Error() != want {
+		t.Errorf("CheckNet(connect, 8005) = %v, want %q", err, want)
+	}
+	if !errors.Is(err, syscall.EACCES) {
+		t.Errorf("errors.Is(%v, EACCES) = false, want true", err)
+	}
+
+	p = Policy{Config: V3}
+	if err := p.CheckNet(ll.AccessNetConnectTCP, 80); err != nil {
+		t.Errorf("CheckNet() without handled network rights = %v, want nil", err)
+	}
+}
+
+func TestPolicyCheckRename(t *testing.T) {
+	p := Policy{
+		Config: V3,
+		Rules: []Rule{
+			RWDirs("/data/a").WithRefer(),
+			RWDirs("/data/b").WithRefer(),
+			RWDirs("/data/c"),
+			PathAccess(ll.AccessFSMakeReg|ll.AccessFSRefer, "/data/e"),
+			PathAccess(ll.AccessFSReadFile|ll.AccessFSRemoveFile|ll.AccessFSRefer, "/data/ro"),
+		},
+	}
+	for _, tt := range []struct {
+		name     string
+		src, dst string
+		isDir    bool
+		want     syscall.Errno
+	}{
+		{"SameDir", "/data/c/x", "/data/c/y", false, 0},
+		{"CrossDir", "/data/a/x", "/data/b/x", false, 0},
+		{"CrossDirDir", "/data/a/x", "/data/b/x", true, 0},
+		{"NoRefer", "/data/a/x", "/data/c/x", false, syscall.EXDEV},
+		{"NoRemove", "/data/e/x", "/data/a/x", false, syscall.EACCES},
+		{"Escalation", "/data/ro/x", "/data/a/x", false, syscall.EXDEV},
+		{"NoEscalation", "/data/a/x", "/data/e/x", false, 0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := p.CheckRename(tt.src, tt.dst, tt.isDir)
+			if tt.want == 0 {
+				if err != nil {
+					t.Errorf("CheckRename(%q, %q) = %v, want nil", tt.src, tt.dst, err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.want) {
+				t.Errorf("CheckRename(%q, %q) = %v, want %v", tt.src, tt.dst, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyCheckLink(t *testing.T) {
+	p := Policy{
+		Config: V3,
+		Rules: []Rule{
+			PathAccess(ll.AccessFSReadFile|ll.AccessFSMakeReg|ll.AccessFSRefer, "/data/ro"),
+			RWDirs("/data/rw").WithRefer(),
+		},
+	}
+	if err := p.CheckLink("/data/rw/x", "/data/rw/y"); err != nil {
+		t.Errorf("CheckLink() in the same directory = %v, want nil", err)
+	}
+	if err := p.CheckLink("/data/rw/x", "/data/ro/x"); err != nil {
+		t.Errorf("CheckLink() to more restricted directory = %v, want nil", err)
+	}
+	err := p.CheckLink("/data/ro/x", "/data/rw/x")
+	if !errors.Is(err, syscall.EXDEV) {
+		t.Errorf("CheckLink() to less restricted directory = %v, want EXDEV", err)
+	}
+	if want := `access {execute,write_file,truncate} denied on "/data/rw" (not granted on /data/ro)`; err == nil || err.Error() != want {
+		t.Errorf("CheckLink() error = %v, want %q", err, want)
+	}
+}
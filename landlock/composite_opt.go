@@ -1,9 +1,28 @@
 package landlock
 
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
 type compositeRule struct {
 	rules []Rule
 }
 
+func (c *compositeRule) String() string {
+	if len(c.rules) > 1 {
+		if s, ok := netRulesString(c.rules); ok {
+			return s
+		}
+	}
+	var parts []string
+	for _, r := range c.rules {
+		parts = append(parts, fmt.Sprintf("%v", r))
+	}
+	return "{" + strings.Join(parts, "; ") + "}"
+}
+
 func (c *compositeRule) compatibleWithConfig(cfg Config) bool {
 	for _, r := range c.rules {
 		if !r.compatibleWithConfig(cfg) {
@@ -45,3 +64,33 @@ func (c *compositeRule) addToRuleset(rulesetFD int, cfg Config) error {
 func CompositeRule(rules ...Rule) Rule {
 	return &compositeRule{rules: rules}
 }
+
+// SubRules returns the sub-rules of a rule created with
+// [CompositeRule].  ok is false if rule is not a composite rule.
+func SubRules(rule Rule) (rules []Rule, ok bool) {
+	cr, ok := rule.(*compositeRule)
+	if !ok {
+		return nil, false
+	}
+	return slices.Clone(cr.rules), true
+}
+
+// FlattenRules returns the given rules with all composite rules
+// replaced by their sub-rules, recursively.
+func FlattenRules(rules ...Rule) []Rule {
+	return flattenRules(rules)
+}
+
+// flattenRules returns the rules with all composite rules replaced by
+// their sub-rules, recursively.
+func flattenRules(rules []Rule) []Rule {
+	var res []Rule
+	for _, r := range rules {
+		if cr, ok := r.(*compositeRule); ok {
+			res = append(res, flattenRules(cr.rules)...)
+			continue
+		}
+		res = append(res, r)
+	}
+	return res
+}
@@ -0,0 +1,25 @@
+package landlock
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSubRules(t *testing.T) {
+	inner := CompositeRule(ConnectTCP(53), RODirs("/etc"))
+	outer := CompositeRule(RODirs("/usr"), inner)
+
+	got, ok := SubRules(outer)
+	if want := []Rule{RODirs("/usr"), inner}; !ok || !reflect.DeepEqual(got, want) {
+		t.Errorf("SubRules() = %v, %v, want %v, true", got, ok, want)
+	}
+	if got, ok := SubRules(RODirs("/usr")); ok || got != nil {
+		t.Errorf("SubRules(FSRule) = %v, %v, want nil, false", got, ok)
+	}
+
+	flat := FlattenRules(outer, BindTCP(80))
+	want := []Rule{RODirs("/usr"), ConnectTCP(53), RODirs("/etc"), BindTCP(80)}
+	if !reflect.DeepEqual(flat, want) {
+		t.Errorf("FlattenRules() = %v, want %v", flat, want)
+	}
+}
@@ -161,12 +161,17 @@ var v0 = Config{}
 // It is recommended to use one of the preset configurations such as
 // [landlock.V9], which restrict the full set of access rights
 // available at this Landlock ABI version.
+//
+// Configs can be compared with ==.  A config with a callback
+// registered through [Config.OnDegrade] is only equal to copies of
+// itself, see there.
 type Config struct {
 	handledAccessFS  AccessFSSet
 	handledAccessNet AccessNetSet
 	scoped           ScopedSet
 	flags            restrictFlagsSet
 	bestEffort       bool
+	onDegrade        *degradeHook
 }
 
 // NewConfig creates a new Landlock configuration with the given parameters.
@@ -273,6 +278,35 @@ func (c Config) String() string {
 	return fmt.Sprintf("{Landlock %v; FS: %v; Net: %v; Scoped: %v%v}", version, fsDesc, netDesc, scopedDesc, extra)
 }
 
+// HandledAccessFS returns the filesystem access rights which are
+// restricted by the Config.
+func (c Config) HandledAccessFS() AccessFSSet {
+	return c.handledAccessFS
+}
+
+// HandledAccessNet returns the network access rights which are
+// restricted by the Config.
+func (c Config) HandledAccessNet() AccessNetSet {
+	return c.handledAccessNet
+}
+
+// Scoped returns the IPC scopes which are restricted by the Config.
+func (c Config) Scoped() ScopedSet {
+	return c.scoped
+}
+
+// Flags returns the names of the logging flags set on the Config,
+// such as "log_new_exec_on" for [Config.EnableLoggingForSubprocesses].
+func (c Config) Flags() []string {
+	return c.flags.names()
+}
+
+// IsBestEffort reports whether the Config is in best effort mode, as
+// set with [Config.BestEffort].
+func (c Config) IsBestEffort() bool {
+	return c.bestEffort
+}
+
 // BestEffort returns a config that will opportunistically enforce
 // the strongest rules it can, up to the given ABI version, working
 // with the level of Landlock support available in the running kernel.
@@ -285,6 +319,32 @@ func (c Config) BestEffort() Config {
 	return cfg
 }
 
+// OnDegrade returns a config that invokes fn whenever a best effort
+// restriction enforces less than the configuration asks for, because
+// the running kernel does not support all of it.
+//
+// fn is invoked synchronously when the ruleset gets prepared (e.g.
+// during [Config.Restrict] or [Config.Prepare]), before it is
+// enforced.  It is not invoked when the configuration can be enforced
+// in full.  The [DegradeEvent] lists the dropped access rights,
+// scopes and flags, and whether Landlock enforcement was given up
+// entirely.
+//
+// This is useful for emitting warnings or metrics when a program
+// runs with weaker sandboxing than intended.
+//
+// The callback is part of the config when comparing configs with ==:
+// The returned config is not equal to c, and it is not equal to
+// configs from other OnDegrade calls either, even if they register
+// the same fn.  Configs which should be compared (e.g. against
+// [landlock.V5]) are best compared before registering the callback.
+// The callback does not show in the config's String representation.
+func (c Config) OnDegrade(fn func(DegradeEvent)) Config {
+	cfg := c
+	cfg.onDegrade = &degradeHook{fn: fn}
+	return cfg
+}
+
 // DisableLoggingForOriginatingProcess disables logging of denied
 // accesses originating from the thread creating the Landlock domain,
 // as well as its children, as long as they continue running the same
@@ -444,6 +504,7 @@ func (c Config) RestrictPaths(rules ...Rule) error {
 		handledAccessFS: c.handledAccessFS,
 		flags:           c.flags,
 		bestEffort:      c.bestEffort,
+		onDegrade:       c.onDegrade,
 	}
 	return restrict(c, rules...)
 }
@@ -478,6 +539,7 @@ func (c Config) RestrictNet(rules ...Rule) error {
 		handledAccessNet: c.handledAccessNet,
 		flags:            c.flags,
 		bestEffort:       c.bestEffort,
+		onDegrade:        c.onDegrade,
 	}
 	return restrict(c, rules...)
 }
@@ -496,6 +558,7 @@ func (c Config) RestrictScoped() error {
 		scoped:     c.scoped,
 		flags:      c.flags,
 		bestEffort: c.bestEffort,
+		onDegrade:  c.onDegrade,
 	}
 	return restrict(c)
 }
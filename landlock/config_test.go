@@ -193,3 +193,45 @@ func TestRestrictTo(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigAccessors(t *testing.T) {
+	c := V5.BestEffort().EnableLoggingForSubprocesses()
+	if got, want := c.HandledAccessFS(), abiInfos[5].supportedAccessFS; got != want {
+		t.Errorf("HandledAccessFS() = %v, want %v", got, want)
+	}
+	if got, want := c.HandledAccessNet(), AccessNetSet(ll.AccessNetBindTCP|ll.AccessNetConnectTCP); got != want {
+		t.Errorf("HandledAccessNet() = %v, want %v", got, want)
+	}
+	if got := c.Scoped(); !got.isEmpty() {
+		t.Errorf("Scoped() = %v, want ∅", got)
+	}
+	if got, want := V6.Scoped(), abiInfos[6].supportedScoped; got != want {
+		t.Errorf("V6.Scoped() = %v, want %v", got, want)
+	}
+	if got := c.Flags(); len(got) != 1 || got[0] != "log_new_exec_on" {
+		t.Errorf("Flags() = %v, want [log_new_exec_on]", got)
+	}
+	if !c.IsBestEffort() {
+		t.Error("IsBestEffort() = false, want true")
+	}
+	if V5.IsBestEffort() {
+		t.Error("V5.IsBestEffort() = true, want false")
+	}
+}
+
+func TestConfigEqualityWithOnDegrade(t *testing.T) {
+	fn := func(DegradeEvent) {}
+	c := V5.BestEffort().OnDegrade(fn)
+	if c == V5.BestEffort() {
+		t.Error("config with OnDegrade callback == config without")
+	}
+	if c == V5.BestEffort().OnDegrade(fn) {
+		t.Error("configs from different OnDegrade calls are equal")
+	}
+	if cc := c; cc != c {
+		t.Error("copy of config with OnDegrade callback != original")
+	}
+	if got, want := c.String(), V5.BestEffort().String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,98 @@
+package landlock
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DegradeEvent describes how a best effort configuration was
+// weakened to work with the running kernel.  It is passed to the
+// callback registered with [Config.OnDegrade].
+type DegradeEvent struct {
+	// ABI is the Landlock ABI version supported by the running
+	// kernel, as used by Go-Landlock.  It is 0 if Landlock is not
+	// available.
+	ABI int
+
+	// Requested is the configuration that was asked for.
+	Requested Config
+
+	// Effective is the configuration which is enforced instead.
+	Effective Config
+
+	// The access rights, scopes and restriction flags which are
+	// handled by Requested, but not by Effective.
+	DroppedAccessFS  AccessFSSet
+	DroppedAccessNet AccessNetSet
+	DroppedScoped    ScopedSet
+	DroppedFlags     []string
+
+	// FellBackToV0 is true if Landlock is not enforced at all,
+	// because a rule asks for the "refer" access right, which is
+	// not available on the running kernel, or because the program
+	// is not running on Linux.
+	FellBackToV0 bool
+}
+
+// degradeHook holds the callback registered with Config.OnDegrade.
+//
+// It is referenced through a pointer, so that Config stays comparable.
+// Configs with a hook are only equal if they share the same pointer.
+type degradeHook struct {
+	fn func(DegradeEvent)
+}
+
+func newDegradeEvent(requested, effective Config, abi abiInfo, fellBack bool) DegradeEvent {
+	return DegradeEvent{
+		ABI:              abi.version,
+		Requested:        requested,
+		Effective:        effective,
+		DroppedAccessFS:  requested.handledAccessFS &^ effective.handledAccessFS,
+		DroppedAccessNet: requested.handledAccessNet &^ effective.handledAccessNet,
+		DroppedScoped:    requested.scoped &^ effective.scoped,
+		DroppedFlags:     (requested.flags &^ effective.flags).names(),
+		FellBackToV0:     fellBack,
+	}
+}
+
+// degraded is true if the event describes an actual weakening.
+func (e DegradeEvent) degraded() bool {
+	return e.FellBackToV0 ||
+		!e.DroppedAccessFS.isEmpty() ||
+		!e.DroppedAccessNet.isEmpty() ||
+		!e.DroppedScoped.isEmpty() ||
+		len(e.DroppedFlags) > 0
+}
+
+// reportDegradation invokes the OnDegrade callback of c, if c was
+// weakened to effective.
+func (c Config) reportDegradation(effective Config, abi abiInfo, fellBack bool) {
+	if c.onDegrade == nil {
+		return
+	}
+	ev := newDegradeEvent(c, effective, abi, fellBack)
+	if !ev.degraded() {
+		return
+	}
+	c.onDegrade.fn(ev)
+}
+
+func (e DegradeEvent) String() string {
+	var parts []string
+	if e.FellBackToV0 {
+		parts = append(parts, "fell back to no enforcement")
+	}
+	if !e.DroppedAccessFS.isEmpty() {
+		parts = append(parts, fmt.Sprintf("dropped FS access rights %v", e.DroppedAccessFS))
+	}
+	if !e.DroppedAccessNet.isEmpty() {
+		parts = append(parts, fmt.Sprintf("dropped network access rights %v", e.DroppedAccessNet))
+	}
+	if !e.DroppedScoped.isEmpty() {
+		parts = append(parts, fmt.Sprintf("dropped scopes %v", e.DroppedScoped))
+	}
+	if len(e.DroppedFlags) > 0 {
+		parts = append(parts, fmt.Sprintf("dropped flags %v", strings.Join(e.DroppedFlags, ",")))
+	}
+	return fmt.Sprintf("Landlock degraded on ABI V%d: %s", e.ABI, strings.Join(parts, "; "))
+}
@@ -0,0 +1,337 @@
+package landlock
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Policy is a configuration together with the rules which are
+// enforced with it.
+type Policy struct {
+	Config Config
+	Rules  []Rule
+}
+
+// resolvedRules returns the rules of the policy with dynamic rules
+// resolved for the policy's Config, composite rules flattened and
+// paths expanded.
+func (p Policy) resolvedRules() ([]Rule, error) {
+	rules, err := resolveDynamicRules(p.Config, p.Rules)
+	if err != nil {
+		return nil, err
+	}
+	return expandRules(flattenRules(rules))
+}
+
+// IsSubsetPolicy reports whether policy a permits no more than policy
+// b, i.e. whether everything which a permits is also permitted by b.
+//
+// This is the case if a restricts at least the access rights and
+// scopes which b restricts, and if a only grants access rights which
+// b grants as well.  Paths are compared lexically, so access granted
+// by a on a path is considered to be permitted by b if b grants it on
+// the same path or on one of its parent directories.  Symlinks are
+// not resolved.
+//
+// Dynamic rules are resolved with the Config of the respective
+// policy, and the paths of rules which use [FSRule.Expand] are
+// expanded.  IsSubsetPolicy returns false if that fails.
+func IsSubsetPolicy(a, b Policy) bool {
+	return checkSubset(a, b) == nil
+}
+
+// checkSubset returns an error describing the first thing which a
+// permits and b does not permit, or nil if a is a subset of b.
+func checkSubset(a, b Policy) error {
+	ac, bc := a.Config, b.Config
+	if d := bc.handledAccessFS &^ ac.handledAccessFS; !d.isEmpty() {
+		return fmt.Errorf("does not restrict %v", d)
+	}
+	if d := bc.handledAccessNet &^ ac.handledAccessNet; !d.isEmpty() {
+		return fmt.Errorf("does not restrict %v", d)
+	}
+	if d := bc.scoped &^ ac.scoped; !d.isEmpty() {
+		return fmt.Errorf("does not restrict scopes %v", d)
+	}
+
+	aRules, err := a.resolvedRules()
+	if err != nil {
+		return err
+	}
+	bRules, err := b.resolvedRules()
+	if err != nil {
+		return err
+	}
+	for _, rule := range aRules {
+		switch r := rule.(type) {
+		case FSRule:
+			want := r.effectiveAccess(ac).intersect(bc.handledAccessFS)
+			for _, path := range r.names() {
+				if d := want &^ grantedFS(bRules, bc, path); !d.isEmpty() {
+					return fmt.Errorf("grants %v on %q", d, path)
+				}
+			}
+		case NetRule:
+			want := r.access.intersect(bc.handledAccessNet)
+			for port := int(r.port); port <= int(r.lastPort()); port++ {
+				if d := want &^ grantedNet(bRules, bc, uint16(port)); !d.isEmpty() {
+					return fmt.Errorf("grants %v on TCP port %v", d, port)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// PolicyDiff describes the differences between two policies.  It is
+// returned by [Diff].
+type PolicyDiff struct {
+	// Access rights and scopes which are restricted by the new
+	// policy but not by the old one, and vice versa.
+	AddedHandledAccessFS    AccessFSSet
+	RemovedHandledAccessFS  AccessFSSet
+	AddedHandledAccessNet   AccessNetSet
+	RemovedHandledAccessNet AccessNetSet
+	AddedScoped             ScopedSet
+	RemovedScoped           ScopedSet
+
+	// Paths lists the paths mentioned in either policy, for which
+	// the permitted access rights differ, sorted by path.
+	Paths []PathDiff
+
+	// Ports lists the TCP port ranges mentioned in either policy,
+	// for which the permitted access rights differ, sorted by port.
+	Ports []PortDiff
+
+	// Unresolved lists the errors of dynamic rules which could not
+	// be resolved and of paths which could not be expanded.  These
+	// rules are not taken into account.
+	Unresolved []string
+}
+
+// PathDiff describes how the access rights which are permitted on a
+// path differ between two policies.
+//
+// Access rights which a policy does not restrict are permitted on all
+// paths, so they are included in Old or New if the other policy
+// restricts them.
+type PathDiff struct {
+	Path     string
+	Old, New AccessFSSet
+}
+
+// PortDiff describes how the access rights which are permitted on the
+// TCP ports from Lo to Hi (inclusive) differ between two policies.
+//
+// Access rights which a policy does not restrict are permitted on all
+// ports, so they are included in Old or New if the other policy
+// restricts them.
+type PortDiff struct {
+	Lo, Hi   uint16
+	Old, New AccessNetSet
+}
+
+// Diff compares two policies and returns their differences.
+//
+// Paths are compared lexically after cleaning them with
+// [path/filepath.Clean].  The access rights which a policy permits on
+// a path include the ones granted on its parent directories, so that
+// policies which express the same permissions through different path
+// prefixes compare equal.  Symlinks are not resolved.
+//
+// Dynamic rules are resolved with the Config of the respective
+// policy, and the paths of rules which use [FSRule.Expand] are
+// expanded.
+func Diff(oldCfg Config, oldRules []Rule, newCfg Config, newRules []Rule) *PolicyDiff {
+	d := &PolicyDiff{
+		AddedHandledAccessFS:    newCfg.handledAccessFS &^ oldCfg.handledAccessFS,
+		RemovedHandledAccessFS:  oldCfg.handledAccessFS &^ newCfg.handledAccessFS,
+		AddedHandledAccessNet:   newCfg.handledAccessNet &^ oldCfg.handledAccessNet,
+		RemovedHandledAccessNet: oldCfg.handledAccessNet &^ newCfg.handledAccessNet,
+		AddedScoped:             newCfg.scoped &^ oldCfg.scoped,
+		RemovedScoped:           oldCfg.scoped &^ newCfg.scoped,
+	}
+	oldRules = d.resolve(oldCfg, oldRules)
+	newRules = d.resolve(newCfg, newRules)
+
+	// Rights which are not handled are permitted everywhere.
+	allFS := oldCfg.handledAccessFS | newCfg.handledAccessFS
+	allNet := oldCfg.handledAccessNet | newCfg.handledAccessNet
+	oldFS := func(path string) AccessFSSet {
+		return grantedFS(oldRules, oldCfg, path) | (allFS &^ oldCfg.handledAccessFS)
+	}
+	newFS := func(path string) AccessFSSet {
+		return grantedFS(newRules, newCfg, path) | (allFS &^ newCfg.handledAccessFS)
+	}
+	oldNet := func(port uint16) AccessNetSet {
+		return grantedNet(oldRules, oldCfg, port) | (allNet &^ oldCfg.handledAccessNet)
+	}
+	newNet := func(port uint16) AccessNetSet {
+		return grantedNet(newRules, newCfg, port) | (allNet &^ newCfg.handledAccessNet)
+	}
+
+	var paths []string
+	ports := make(map[uint16]bool)
+	for _, rule := range append(slices.Clone(oldRules), newRules...) {
+		switch r := rule.(type) {
+		case FSRule:
+			for _, p := range r.names() {
+				paths = append(paths, filepath.Clean(p))
+			}
+		case NetRule:
+			for port := int(r.port); port <= int(r.lastPort()); port++ {
+				ports[uint16(port)] = true
+			}
+		}
+	}
+	slices.Sort(paths)
+	for _, p := range slices.Compact(paths) {
+		if o, n := oldFS(p), newFS(p); o != n {
+			d.Paths = append(d.Paths, PathDiff{Path: p, Old: o, New: n})
+		}
+	}
+
+	var sortedPorts []uint16
+	for p := range ports {
+		sortedPorts = append(sortedPorts, p)
+	}
+	slices.Sort(sortedPorts)
+	for _, p := range sortedPorts {
+		o, n := oldNet(p), newNet(p)
+		if o == n {
+			continue
+		}
+		// Merge consecutive ports with the same change.
+		if l := len(d.Ports); l > 0 {
+			last := &d.Ports[l-1]
+			if int(last.Hi)+1 == int(p) && last.Old == o && last.New == n {
+				last.Hi = p
+				continue
+			}
+		}
+		d.Ports = append(d.Ports, PortDiff{Lo: p, Hi: p, Old: o, New: n})
+	}
+	return d
+}
+
+// resolve returns the rules with dynamic rules resolved for c,
+// composite rules flattened and paths expanded.  Rules which can not
+// be resolved are recorded in d.Unresolved and skipped.
+func (d *PolicyDiff) resolve(c Config, rules []Rule) []Rule {
+	var res []Rule
+	for _, rule := range rules {
+		resolved, err := resolveDynamicRules(c, []Rule{rule})
+		if err == nil {
+			resolved, err = expandRules(flattenRules(resolved))
+		}
+		if err != nil {
+			d.Unresolved = append(d.Unresolved, err.Error())
+			continue
+		}
+		res = append(res, resolved...)
+	}
+	return res
+}
+
+// Empty reports whether the two compared policies are equivalent.
+func (d *PolicyDiff) Empty() bool {
+	return d.AddedHandledAccessFS.isEmpty() && d.RemovedHandledAccessFS.isEmpty() &&
+		d.AddedHandledAccessNet.isEmpty() && d.RemovedHandledAccessNet.isEmpty() &&
+		d.AddedScoped.isEmpty() && d.RemovedScoped.isEmpty() &&
+		len(d.Paths) == 0 && len(d.Ports) == 0 && len(d.Unresolved) == 0
+}
+
+// String returns a human-readable multi-line description of the
+// differences, with lines for loosened permissions marked by "+",
+// lines for tightened permissions marked by "-", and unresolved
+// dynamic rules marked by "!".
+func (d *PolicyDiff) String() string {
+	var b strings.Builder
+	line := func(loosened bool, format string, args ...any) {
+		mark := "-"
+		if loosened {
+			mark = "+"
+		}
+		fmt.Fprintf(&b, "%s "+format+"\n", append([]any{mark}, args...)...)
+	}
+	if !d.RemovedHandledAccessFS.isEmpty() {
+		line(true, "no longer restricted: %v", d.RemovedHandledAccessFS)
+	}
+	if !d.RemovedHandledAccessNet.isEmpty() {
+		line(true, "no longer restricted: %v", d.RemovedHandledAccessNet)
+	}
+	if !d.RemovedScoped.isEmpty() {
+		line(true, "no longer restricted: scopes %v", d.RemovedScoped)
+	}
+	if !d.AddedHandledAccessFS.isEmpty() {
+		line(false, "newly restricted: %v", d.AddedHandledAccessFS)
+	}
+	if !d.AddedHandledAccessNet.isEmpty() {
+		line(false, "newly restricted: %v", d.AddedHandledAccessNet)
+	}
+	if !d.AddedScoped.isEmpty() {
+		line(false, "newly restricted: scopes %v", d.AddedScoped)
+	}
+	for _, pd := range d.Paths {
+		if added := pd.New &^ pd.Old; !added.isEmpty() {
+			line(true, "%v: %v", pd.Path, added)
+		}
+		if removed := pd.Old &^ pd.New; !removed.isEmpty() {
+			line(false, "%v: %v", pd.Path, removed)
+		}
+	}
+	for _, pd := range d.Ports {
+		ports := NetRule{port: pd.Lo, extra: pd.Hi - pd.Lo}.portsString()
+		if added := pd.New &^ pd.Old; !added.isEmpty() {
+			line(true, "TCP port %v: %v", ports, added)
+		}
+		if removed := pd.Old &^ pd.New; !removed.isEmpty() {
+			line(false, "TCP port %v: %v", ports, removed)
+		}
+	}
+	for _, e := range d.Unresolved {
+		fmt.Fprintf(&b, "! %v\n", e)
+	}
+	return b.String()
+}
+
+// grantedFS returns the access rights which rules grant for path in a
+// ruleset for c, based on a lexical comparison of paths.
+func grantedFS(rules []Rule, c Config, path string) AccessFSSet {
+	var a AccessFSSet
+	for _, rule := range rules {
+		r, ok := rule.(FSRule)
+		if !ok {
+			continue
+		}
+		for _, p := range r.names() {
+			if isBeneath(path, p) {
+				a = a.union(r.effectiveAccess(c))
+			}
+		}
+	}
+	return a
+}
+
+// grantedNet returns the access rights which rules grant for port in
+// a ruleset for c.
+func grantedNet(rules []Rule, c Config, port uint16) AccessNetSet {
+	var a AccessNetSet
+	for _, rule := range rules {
+		if r, ok := rule.(NetRule); ok && r.containsPort(port) {
+			a |= r.access.intersect(c.handledAccessNet)
+		}
+	}
+	return a
+}
+
+// isBeneath reports whether path is equal to or lexically beneath dir.
+func isBeneath(path, dir string) bool {
+	path, dir = filepath.Clean(path), filepath.Clean(dir)
+	if path == dir || dir == "/" {
+		return true
+	}
+	return strings.HasPrefix(path, dir+string(filepath.Separator))
+}
@@ -0,0 +1,139 @@
+package landlock
+
+import (
+	"strings"
+	"testing"
+
+	ll "github.com/landlock-lsm/go-landlock/landlock/syscall"
+)
+
+func TestIsSubsetPolicy(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		a, b Policy
+		want bool
+	}{
+		{
+			name: "Same",
+			a:    Policy{V3, []Rule{RODirs("/usr")}},
+			b:    Policy{V3, []Rule{RODirs("/usr")}},
+			want: true,
+		},
+		{
+			name: "NarrowerPath",
+			a:    Policy{V3, []Rule{RODirs("/usr/lib")}},
+			b:    Policy{V3, []Rule{RODirs("/usr")}},
+			want: true,
+		},
+		{
+			name: "WiderPath",
+			a:    Policy{V3, []Rule{RODirs("/usr")}},
+			b:    Policy{V3, []Rule{RODirs("/usr/lib")}},
+			want: false,
+		},
+		{
+			name: "FewerHandledRights",
+			a:    Policy{V1, nil},
+			b:    Policy{V3, nil},
+			want: false,
+		},
+		{
+			name: "PortRange",
+			a:    Policy{V4, []Rule{ConnectTCP(8081)}},
+			b:    Policy{V4, []Rule{ConnectTCPRange(8080, 8090)}},
+			want: true,
+		},
+		{
+			name: "WiderPortRange",
+			a:    Policy{V4, []Rule{ConnectTCPRange(8080, 8091)}},
+			b:    Policy{V4, []Rule{ConnectTCPRange(8080, 8090)}},
+			want: false,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsSubsetPolicy(tt.a, tt.b); got != tt.want {
+				t.Errorf("IsSubsetPolicy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	d := Diff(
+		V4, []Rule{RWDirs("/srv"), RODirs("/usr"), ConnectTCPRange(80, 82), BindTCP(8080)},
+		V4, []Rule{RODirs("/srv/data/"), RODirs("/usr/lib", "/usr"), ConnectTCPRange(80, 81), BindTCP(8080), BindTCP(9090)},
+	)
+	if !d.AddedHandledAccessFS.isEmpty() || !d.RemovedHandledAccessFS.isEmpty() {
+		t.Errorf("handled FS rights changed: %+v", d)
+	}
+
+	rw := RWDirs("/").effectiveAccess(V4)
+	ro := RODirs("/").effectiveAccess(V4)
+	wantPaths := []PathDiff{
+		{Path: "/srv", Old: rw, New: 0},
+		{Path: "/srv/data", Old: rw, New: ro},
+	}
+	if len(d.Paths) != len(wantPaths) {
+		t.Fatalf("Paths = %+v, want %+v", d.Paths, wantPaths)
+	}
+	for i, pd := range d.Paths {
+		if pd != wantPaths[i] {
+			t.Errorf("Paths[%d] = %+v, want %+v", i, pd, wantPaths[i])
+		}
+	}
+
+	wantPorts := []PortDiff{
+		{Lo: 82, Hi: 82, Old: AccessNetSet(ll.AccessNetConnectTCP), New: 0},
+		{Lo: 9090, Hi: 9090, Old: 0, New: AccessNetSet(ll.AccessNetBindTCP)},
+	}
+	if len(d.Ports) != len(wantPorts) {
+		t.Fatalf("Ports = %+v, want %+v", d.Ports, wantPorts)
+	}
+	for i, pd := range d.Ports {
+		if pd != wantPorts[i] {
+			t.Errorf("Ports[%d] = %+v, want %+v", i, pd, wantPorts[i])
+		}
+	}
+
+	s := d.String()
+	for _, want := range []string{
+		"- /srv: {",
+		"+ TCP port 9090: {bind_tcp}",
+		"- TCP port 82: {connect_tcp}",
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("String() = %q, missing %q", s, want)
+		}
+	}
+}
+
+func TestDiffHandledRights(t *testing.T) {
+	d := Diff(V3, []Rule{RODirs("/usr")}, V1, []Rule{RODirs("/usr")})
+	if d.Empty() {
+		t.Fatal("Diff() is empty")
+	}
+	if want := V3.handledAccessFS &^ V1.handledAccessFS; d.RemovedHandledAccessFS != want {
+		t.Errorf("RemovedHandledAccessFS = %v, want %v", d.RemovedHandledAccessFS, want)
+	}
+	// The refer and truncate rights are unrestricted in the new
+	// policy, so they are now permitted on /usr as well.
+	if len(d.Paths) != 1 || d.Paths[0].Path != "/usr" {
+		t.Fatalf("Paths = %+v, want a single entry for /usr", d.Paths)
+	}
+	if got := d.Paths[0].New &^ d.Paths[0].Old; got != d.RemovedHandledAccessFS {
+		t.Errorf("newly permitted on /usr: %v, want %v", got, d.RemovedHandledAccessFS)
+	}
+	if !strings.Contains(d.String(), "+ no longer restricted: {") {
+		t.Errorf("String() = %q", d.String())
+	}
+}
+
+func TestDiffEquivalent(t *testing.T) {
+	d := Diff(
+		V5, []Rule{RODirs("/usr"), ConnectTCPPorts(53, 80)},
+		V5, []Rule{RODirs("/usr/"), RODirs("/usr/lib"), ConnectTCP(80), ConnectTCP(53)},
+	)
+	if !d.Empty() {
+		t.Errorf("Diff() = %v, want empty", d)
+	}
+}
@@ -0,0 +1,143 @@
+package landlock
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"runtime"
+)
+
+// maxDynamicRuleDepth limits how deeply dynamic rules may resolve to
+// further dynamic rules.
+const maxDynamicRuleDepth = 8
+
+type dynamicRule struct {
+	name string
+	fn   func(Config) ([]Rule, error)
+}
+
+// DynamicRule returns a [Rule] whose sub-rules are computed by fn at
+// the time when the ruleset is prepared, e.g. during
+// [Config.Restrict] or [Config.Prepare].  This makes it possible to
+// grant access to paths and ports which are discovered at runtime,
+// for example from a service registry.
+//
+// fn is called with the Config which is going to be enforced: In best
+// effort mode, this is the Config after downgrading it to what the
+// running kernel supports, so that fn can decide which rules to
+// return based on the available access rights.  The returned rules
+// are then subject to the same compatibility checks and best effort
+// downgrades as rules which are passed directly.  They may include
+// composite rules and further dynamic rules.
+//
+// The name identifies the rule in error messages and reports.  If fn
+// returns an error, preparing the ruleset fails with that error,
+// wrapped with the name.
+func DynamicRule(name string, fn func(Config) ([]Rule, error)) Rule {
+	return &dynamicRule{name: name, fn: fn}
+}
+
+// Lazy returns a [Rule] whose sub-rules are computed by fn at the
+// time when the ruleset is prepared, after the Config was downgraded
+// to what the running kernel supports in best effort mode.  This lets
+// rule libraries adapt to the available access rights, or to the
+// environment of the process:
+//
+//	landlock.Lazy(func(c landlock.Config) ([]landlock.Rule, error) {
+//	    dir := os.Getenv("XDG_RUNTIME_DIR")
+//	    if dir == "" {
+//	        return nil, errors.New("XDG_RUNTIME_DIR is not set")
+//	    }
+//	    return []landlock.Rule{landlock.RWDirs(dir)}, nil
+//	})
+//
+// Lazy is like [DynamicRule], but the rule is named after the place
+// where Lazy is called, such as "rules.XDGRuntime (xdg.go:42)".
+// Errors returned by fn are wrapped with that name.
+func Lazy(fn func(Config) ([]Rule, error)) Rule {
+	return DynamicRule(callerName(1), fn)
+}
+
+// callerName describes the calling function skip frames above the
+// caller of callerName, without its package path.
+func callerName(skip int) string {
+	pc, file, line, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return "lazy rule"
+	}
+	name := "?"
+	if f := runtime.FuncForPC(pc); f != nil {
+		name = path.Base(f.Name())
+	}
+	return fmt.Sprintf("%s (%s:%d)", name, filepath.Base(file), line)
+}
+
+func (r *dynamicRule) String() string {
+	return fmt.Sprintf("DYNAMIC %q", r.name)
+}
+
+// compatibleWithConfig is true, because compatibility is checked on
+// the resolved rules.
+func (r *dynamicRule) compatibleWithConfig(c Config) bool {
+	return true
+}
+
+func (r *dynamicRule) downgrade(c Config) (out Rule, ok bool) {
+	return r, true
+}
+
+func (r *dynamicRule) addToRuleset(rulesetFD int, c Config) error {
+	return bug(fmt.Errorf("dynamic rule %q was not resolved", r.name))
+}
+
+// resolveDynamicRules returns the rules with all dynamic rules
+// replaced by the rules they resolve to for c, recursively.
+func resolveDynamicRules(c Config, rules []Rule) ([]Rule, error) {
+	return resolveDynamicRulesDepth(c, rules, 0)
+}
+
+func resolveDynamicRulesDepth(c Config, rules []Rule, depth int) ([]Rule, error) {
+	if depth > maxDynamicRuleDepth {
+		return nil, errors.New("dynamic rules are nested too deeply")
+	}
+	res := make([]Rule, 0, len(rules))
+	for _, rule := range rules {
+		switch r := rule.(type) {
+		case *dynamicRule:
+			sub, err := r.fn(c)
+			if err != nil {
+				return nil, fmt.Errorf("dynamic rule %q: %w", r.name, err)
+			}
+			sub, err = resolveDynamicRulesDepth(c, sub, depth+1)
+			if err != nil {
+				return nil, fmt.Errorf("dynamic rule %q: %w", r.name, err)
+			}
+			res = append(res, sub...)
+		case *compositeRule:
+			if !hasDynamicRules(r.rules) {
+				res = append(res, r)
+				continue
+			}
+			sub, err := resolveDynamicRulesDepth(c, r.rules, depth)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, CompositeRule(sub...))
+		default:
+			res = append(res, rule)
+		}
+	}
+	return res, nil
+}
+
+// hasDynamicRules reports whether rules contain any dynamic rules,
+// including within composite rules.
+func hasDynamicRules(rules []Rule) bool {
+	for _, rule := range flattenRules(rules) {
+		if _, ok := rule.(*dynamicRule); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+package landlock
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+
+	ll "github.com/landlock-lsm/go-landlock/landlock/syscall"
+)
+
+func TestResolveDynamicRules(t *testing.T) {
+	var gotCfg Config
+	dyn := DynamicRule("registry", func(c Config) ([]Rule, error) {
+		gotCfg = c
+		return []Rule{ConnectTCP(5432), DynamicRule("nested", func(Config) ([]Rule, error) {
+			return []Rule{RODirs("/srv")}, nil
+		})}, nil
+	})
+	rules, err := resolveDynamicRules(V5, []Rule{RODirs("/usr"), CompositeRule(dyn, BindTCP(80))})
+	if err != nil {
+		t.Fatalf("resolveDynamicRules(): %v", err)
+	}
+	if gotCfg != V5 {
+		t.Errorf("fn got Config %v, want %v", gotCfg, V5)
+	}
+	want := []Rule{RODirs("/usr"), CompositeRule(ConnectTCP(5432), RODirs("/srv"), BindTCP(80))}
+	if !reflect.DeepEqual(rules, want) {
+		t.Errorf("resolveDynamicRules() = %v, want %v", rules, want)
+	}
+}
+
+func TestResolveDynamicRulesErrors(t *testing.T) {
+	errRegistry := errors.New("registry unavailable")
+	failing := DynamicRule("registry", func(Config) ([]Rule, error) {
+		return nil, errRegistry
+	})
+	_, err := resolveDynamicRules(V5, []Rule{failing})
+	if !errors.Is(err, errRegistry) || !strings.Contains(err.Error(), `dynamic rule "registry"`) {
+		t.Errorf("resolveDynamicRules() = %v, want error wrapping %v", err, errRegistry)
+	}
+
+	var loop Rule
+	loop = DynamicRule("loop", func(Config) ([]Rule, error) {
+		return []Rule{loop}, nil
+	})
+	if _, err := resolveDynamicRules(V5, []Rule{loop}); err == nil {
+		t.Error("resolveDynamicRules() with endless recursion succeeded, want error")
+	}
+}
+
+func TestExplainDynamicRule(t *testing.T) {
+	var gotCfg Config
+	dyn := DynamicRule("net", func(c Config) ([]Rule, error) {
+		gotCfg = c
+		if c.HandledAccessNet().isEmpty() {
+			return []Rule{RODirs("/")}, nil
+		}
+		return []Rule{RODirs("/"), ConnectTCP(443)}, nil
+	})
+	rep, err := explain(V5.BestEffort(), []Rule{dyn}, abiInfos[3])
+	if err != nil {
+		t.Fatalf("explain(): %v", err)
+	}
+	if want := abiInfos[3].asConfig().BestEffort(); gotCfg != want {
+		t.Errorf("fn got Config %v, want downgraded %v", gotCfg, want)
+	}
+	if rules := explicitRuleReports(rep); len(rules) != 1 || !reflect.DeepEqual(rules[0].Rule, RODirs("/")) {
+		t.Errorf("Rules = %v, want the resolved rule only", rules)
+	}
+
+	// Resolved rules are checked like rules passed directly.
+	bad := DynamicRule("bad", func(Config) ([]Rule, error) {
+		return []Rule{ConnectTCP(443)}, nil
+	})
+	var ire *IncompatibleRuleError
+	if _, err := explain(V3, []Rule{bad}, abiInfos[3]); !errors.As(err, &ire) {
+		t.Errorf("explain() with incompatible resolved rule = %v, want IncompatibleRuleError", err)
+	}
+}
+
+func TestPolicyDynamicRule(t *testing.T) {
+	dyn := DynamicRule("data", func(Config) ([]Rule, error) {
+		return []Rule{RWDirs("/srv/data")}, nil
+	})
+	p := Policy{Config: V5, Rules: []Rule{dyn}}
+	if err := p.Check(accessFSReadWrite, "/srv/data/x"); err != nil {
+		t.Errorf("Check() = %v, want nil", err)
+	}
+	if IsSubsetPolicy(p, Policy{Config: V5, Rules: []Rule{RODirs("/srv")}}) {
+		t.Error("IsSubsetPolicy() = true, want false")
+	}
+
+	failing := DynamicRule("failing", func(Config) ([]Rule, error) {
+		return nil, errors.New("oops")
+	})
+	d := Diff(V5, []Rule{dyn}, V5, []Rule{dyn, failing})
+	if len(d.Unresolved) != 1 || d.Empty() {
+		t.Errorf("Diff() = %+v, want one unresolved rule", d)
+	}
+}
+
+func ttyRules(c Config) ([]Rule, error) {
+	if c.HandledAccessFS().intersect(ll.AccessFSIoctlDev).isEmpty() {
+		return []Rule{RWFiles("/dev/tty")}, nil
+	}
+	return []Rule{RWFiles("/dev/tty").WithIoctlDev()}, nil
+}
+
+func TestLazy(t *testing.T) {
+	_, _, line, _ := runtime.Caller(0)
+	rule := Lazy(ttyRules)
+	want := fmt.Sprintf(`DYNAMIC "landlock.TestLazy (dynamic_opt_test.go:%d)"`, line+1)
+	if got := fmt.Sprint(rule); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	for _, tt := range []struct {
+		cfg  Config
+		want Rule
+	}{
+		{V4, RWFiles("/dev/tty")},
+		{V5, RWFiles("/dev/tty").WithIoctlDev()},
+	} {
+		got, err := resolveDynamicRules(tt.cfg, []Rule{rule})
+		if err != nil {
+			t.Fatalf("resolveDynamicRules(%v): %v", tt.cfg, err)
+		}
+		if !reflect.DeepEqual(got, []Rule{tt.want}) {
+			t.Errorf("resolveDynamicRules(%v) = %v, want [%v]", tt.cfg, got, tt.want)
+		}
+	}
+
+	// With best effort, the rule sees the downgraded Config.
+	rep, err := explain(V5.BestEffort(), []Rule{rule}, abiInfos[4])
+	if err != nil {
+		t.Fatalf("explain(): %v", err)
+	}
+	if got := rep.Rules[0].Rule; !reflect.DeepEqual(got, RWFiles("/dev/tty")) {
+		t.Errorf("explain() resolved rule %v, want %v", got, RWFiles("/dev/tty"))
+	}
+}
+
+func TestDynamicRuleError(t *testing.T) {
+	rule := DynamicRule("home", func(Config) ([]Rule, error) {
+		return nil, errors.New("HOME is not set")
+	})
+	_, err := resolveDynamicRules(V5, []Rule{rule})
+	if want := `dynamic rule "home": HOME is not set`; err == nil || err.Error() != want {
+		t.Errorf("resolveDynamicRules() = %v, want %q", err, want)
+	}
+}
+
+func homeRule() Rule {
+	return Lazy(func(Config) ([]Rule, error) {
+		return nil, errors.New("HOME is not set")
+	})
+}
+
+func TestLazyError(t *testing.T) {
+	_, err := resolveDynamicRules(V5, []Rule{homeRule()})
+	want := regexp.MustCompile(`^dynamic rule "landlock\.homeRule \(dynamic_opt_test\.go:\d+\)": HOME is not set$`)
+	if err == nil || !want.MatchString(err.Error()) {
+		t.Errorf("resolveDynamicRules() = %v, want match for %v", err, want)
+	}
+}
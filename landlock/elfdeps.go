@@ -0,0 +1,361 @@
+package landlock
+
+import (
+	"bufio"
+	"debug/elf"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ldSoConfPath is the dynamic linker configuration file, which lists
+// additional library directories.
+var ldSoConfPath = "/etc/ld.so.conf"
+
+// ldSoCachePath is the dynamic linker's cache of the libraries in the
+// configured directories.
+var ldSoCachePath = "/etc/ld.so.cache"
+
+// ExecutableDeps returns a [Rule] which grants the rights to execute
+// and read the executable at path, its dynamic linker, and all the
+// shared libraries which it depends on.
+//
+// The dependencies are determined by parsing the executable's ELF
+// headers: The PT_INTERP program header names the dynamic linker, and
+// the DT_NEEDED entries name the shared libraries, which are resolved
+// recursively.  Like the dynamic linker, ExecutableDeps searches for
+// libraries in the directories given in DT_RPATH entries (including
+// the ones of the objects higher up in the load chain) and DT_RUNPATH
+// entries, in the LD_LIBRARY_PATH environment variable (at the time
+// of the call), in the directories listed in /etc/ld.so.conf, and in
+// the default library directories.  If a library has variants in
+// glibc-hwcaps subdirectories, all of them are included, as the
+// variant which gets loaded depends on the CPU.  The rule also covers
+// /etc/ld.so.cache, which the dynamic linker reads on startup.
+//
+// In DT_RPATH and DT_RUNPATH, $ORIGIN is expanded to the directory of
+// the loading object.  On x86 and arm64, $PLATFORM is expanded to the
+// processor name of the executable's machine type.  The value of $LIB
+// is built into the dynamic linker, so it is expanded to each of
+// "lib", "lib64" and the Debian multiarch directory in turn.
+//
+// Libraries which are loaded at runtime with dlopen(3), such as NSS
+// modules, are not included.
+//
+// ExecutableDeps returns an error if the executable can not be parsed
+// or if a library can not be found.  For statically linked
+// executables, the rule only covers the executable itself.
+func ExecutableDeps(path string) (FSRule, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return FSRule{}, err
+	}
+	f, err := elf.Open(abs)
+	if err != nil {
+		return FSRule{}, fmt.Errorf("executable dependencies: %w", err)
+	}
+	defer f.Close()
+
+	r := &libResolver{
+		class:   f.Class,
+		machine: f.Machine,
+		seen:    map[string]bool{abs: true},
+		paths:   []string{abs},
+	}
+	interp, err := elfInterp(f)
+	if err != nil {
+		return FSRule{}, fmt.Errorf("executable dependencies of %v: %w", abs, err)
+	}
+	if interp == "" {
+		return ROFiles(r.paths...), nil // Statically linked.
+	}
+	r.add(interp)
+	if _, err := os.Stat(ldSoCachePath); err == nil {
+		r.add(ldSoCachePath)
+	}
+	r.ldConfDirs = readLdSoConf(ldSoConfPath)
+	if err := r.addNeeded(f, abs, nil); err != nil {
+		return FSRule{}, fmt.Errorf("executable dependencies of %v: %w", abs, err)
+	}
+	return ROFiles(r.paths...), nil
+}
+
+// libResolver resolves the shared library dependencies of ELF files
+// of a given class and machine.
+type libResolver struct {
+	class      elf.Class
+	machine    elf.Machine
+	ldConfDirs []string
+	seen       map[string]bool
+	paths      []string // Resolved files, in the order of discovery.
+}
+
+func (r *libResolver) add(path string) bool {
+	if r.seen[path] {
+		return false
+	}
+	r.seen[path] = true
+	r.paths = append(r.paths, path)
+	return true
+}
+
+// addNeeded resolves the DT_NEEDED entries of f, which was loaded from
+// path, and recursively the ones of the resolved libraries.
+// inherited are the DT_RPATH directories of the objects which loaded
+// f, directly or indirectly.
+func (r *libResolver) addNeeded(f *elf.File, path string, inherited []string) error {
+	needed, err := f.ImportedLibraries()
+	if err != nil {
+		return err
+	}
+	rpath, err := f.DynString(elf.DT_RPATH)
+	if err != nil {
+		return err
+	}
+	runpath, err := f.DynString(elf.DT_RUNPATH)
+	if err != nil {
+		return err
+	}
+
+	// As in the dynamic linker, DT_RPATH is ignored for objects which
+	// have a DT_RUNPATH, and DT_RUNPATH only applies to the object's
+	// own dependencies.
+	origin := filepath.Dir(path)
+	rpathDirs := inherited
+	if len(runpath) == 0 {
+		rpathDirs = slices.Concat(r.expandDST(rpath, origin), inherited)
+	}
+	searchPath := r.searchPath(rpathDirs, r.expandDST(runpath, origin))
+
+	for _, name := range needed {
+		libs, err := r.find(name, searchPath)
+		if err != nil {
+			return err
+		}
+		for _, lib := range libs {
+			if !r.add(lib) {
+				continue
+			}
+			lf, err := elf.Open(lib)
+			if err != nil {
+				return err
+			}
+			err = r.addNeeded(lf, lib, rpathDirs)
+			lf.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// searchPath returns the directories in which libraries are searched,
+// in the order used by the dynamic linker, given the DT_RPATH
+// directories of the load chain and the DT_RUNPATH directories of the
+// loading object.
+func (r *libResolver) searchPath(rpathDirs, runpathDirs []string) []string {
+	var dirs []string
+	if len(runpathDirs) == 0 {
+		dirs = append(dirs, rpathDirs...)
+	}
+	dirs = append(dirs, filepath.SplitList(os.Getenv("LD_LIBRARY_PATH"))...)
+	dirs = append(dirs, runpathDirs...)
+	dirs = append(dirs, r.ldConfDirs...)
+	if r.class == elf.ELFCLASS64 {
+		dirs = append(dirs, "/lib64", "/usr/lib64")
+	}
+	dirs = append(dirs, "/lib", "/usr/lib")
+	return dirs
+}
+
+// elfPlatforms are the values of $PLATFORM (the AT_PLATFORM auxiliary
+// vector entry) for machine types where it does not depend on the CPU
+// model.
+var elfPlatforms = map[elf.Machine]string{
+	elf.EM_386:     "i686",
+	elf.EM_X86_64:  "x86_64",
+	elf.EM_AARCH64: "aarch64",
+}
+
+// multiarchDirs are the Debian multiarch library directories for the
+// supported machine types.
+var multiarchDirs = map[elf.Machine]string{
+	elf.EM_386:     "lib/i386-linux-gnu",
+	elf.EM_X86_64:  "lib/x86_64-linux-gnu",
+	elf.EM_AARCH64: "lib/aarch64-linux-gnu",
+	elf.EM_PPC64:   "lib/powerpc64le-linux-gnu",
+	elf.EM_S390:    "lib/s390x-linux-gnu",
+	elf.EM_RISCV:   "lib/riscv64-linux-gnu",
+}
+
+// expandDST splits the colon-separated path lists and expands the
+// dynamic string tokens $ORIGIN, $PLATFORM and $LIB in them.  $ORIGIN
+// is replaced with the directory of the loading object.  Directories
+// containing $LIB are returned once for each candidate value.
+func (r *libResolver) expandDST(lists []string, origin string) []string {
+	libs := []string{"lib", "lib64"}
+	if d, ok := multiarchDirs[r.machine]; ok {
+		libs = append(libs, d)
+	}
+	var dirs []string
+	for _, list := range lists {
+		for _, dir := range filepath.SplitList(list) {
+			dir = replaceDST(dir, "ORIGIN", origin)
+			if p, ok := elfPlatforms[r.machine]; ok {
+				dir = replaceDST(dir, "PLATFORM", p)
+			}
+			if !strings.Contains(dir, "$LIB") && !strings.Contains(dir, "${LIB}") {
+				dirs = append(dirs, dir)
+				continue
+			}
+			for _, lib := range libs {
+				dirs = append(dirs, replaceDST(dir, "LIB", lib))
+			}
+		}
+	}
+	return dirs
+}
+
+// replaceDST replaces the dynamic string token $name or ${name} in s.
+func replaceDST(s, name, value string) string {
+	s = strings.ReplaceAll(s, "${"+name+"}", value)
+	return strings.ReplaceAll(s, "$"+name, value)
+}
+
+// find locates the library with the given DT_NEEDED name, skipping
+// files which do not match the ELF class and machine.  It returns the
+// library in the first matching search directory, together with its
+// variants in the directory's glibc-hwcaps subdirectories.
+func (r *libResolver) find(name string, searchPath []string) ([]string, error) {
+	if strings.Contains(name, "/") {
+		if r.matches(name) {
+			abs, err := filepath.Abs(name)
+			if err != nil {
+				return nil, err
+			}
+			return []string{abs}, nil
+		}
+		return nil, fmt.Errorf("shared library %q not found", name)
+	}
+	for _, dir := range searchPath {
+		if dir == "" {
+			continue
+		}
+		var candidates []string
+		hwcaps := filepath.Join(dir, "glibc-hwcaps")
+		entries, _ := os.ReadDir(hwcaps)
+		for _, e := range entries {
+			candidates = append(candidates, filepath.Join(hwcaps, e.Name(), name))
+		}
+		candidates = append(candidates, filepath.Join(dir, name))
+
+		var found []string
+		for _, c := range candidates {
+			if !r.matches(c) {
+				continue
+			}
+			abs, err := filepath.Abs(c)
+			if err != nil {
+				return nil, err
+			}
+			found = append(found, abs)
+		}
+		if len(found) > 0 {
+			return found, nil
+		}
+	}
+	return nil, fmt.Errorf("shared library %q not found", name)
+}
+
+// matches reports whether path is an ELF file of the resolver's class
+// and machine.
+func (r *libResolver) matches(path string) bool {
+	f, err := elf.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	return f.Class == r.class && f.Machine == r.machine
+}
+
+// elfInterp returns the dynamic linker named in the PT_INTERP program
+// header, or "" if there is none.
+func elfInterp(f *elf.File) (string, error) {
+	for _, p := range f.Progs {
+		if p.Type != elf.PT_INTERP {
+			continue
+		}
+		buf := make([]byte, p.Filesz)
+		if _, err := p.ReadAt(buf, 0); err != nil {
+			return "", fmt.Errorf("reading PT_INTERP: %w", err)
+		}
+		interp := strings.TrimRight(string(buf), "\x00")
+		if interp == "" {
+			return "", errors.New("empty PT_INTERP")
+		}
+		return interp, nil
+	}
+	return "", nil
+}
+
+// readLdSoConf returns the library directories listed in the
+// ld.so.conf file at path, following "include" directives.  Each
+// directory is only returned once, and files which are included
+// repeatedly are only read once.  Unreadable files are skipped.
+func readLdSoConf(path string) []string {
+	c := ldSoConf{files: make(map[string]bool), seen: make(map[string]bool)}
+	c.read(path)
+	return c.dirs
+}
+
+type ldSoConf struct {
+	files map[string]bool // Files which were read already.
+	seen  map[string]bool // Directories in dirs.
+	dirs  []string
+}
+
+func (c *ldSoConf) read(path string) {
+	path = filepath.Clean(path)
+	if c.files[path] {
+		return // Guard against include loops.
+	}
+	c.files[path] = true
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line, _, _ := strings.Cut(sc.Text(), "#")
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+			continue
+		case fields[0] == "include":
+			for _, pattern := range fields[1:] {
+				if !filepath.IsAbs(pattern) {
+					pattern = filepath.Join(filepath.Dir(path), pattern)
+				}
+				matches, _ := filepath.Glob(pattern)
+				for _, m := range matches {
+					c.read(m)
+				}
+			}
+		case fields[0] == "hwcap":
+			continue // Obsolete directive.
+		default:
+			for _, dir := range fields {
+				if !c.seen[dir] {
+					c.seen[dir] = true
+					c.dirs = append(c.dirs, dir)
+				}
+			}
+		}
+	}
+}
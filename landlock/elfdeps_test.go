@@ -0,0 +1,203 @@
+//go:build linux
+
+package landlock
+
+import (
+	"debug/elf"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/landlock-lsm/go-landlock/landlock/lltest"
+)
+
+func TestReadLdSoConf(t *testing.T) {
+	dir := t.TempDir()
+	confd := filepath.Join(dir, "ld.so.conf.d")
+	if err := os.Mkdir(confd, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"ld.so.conf":          "include ld.so.conf.d/*.conf\n/opt/lib # trailing comment\nhwcap 0 nosegneg\n",
+		"ld.so.conf.d/a.conf": "# comment\n/usr/local/lib\n\n",
+		"ld.so.conf.d/b.conf": "/lib/x86_64-linux-gnu /usr/lib/x86_64-linux-gnu\ninclude " + filepath.Join(dir, "ld.so.conf") + "\n",
+		"ld.so.conf.d/c.txt":  "/not/included\n",
+		"ld.so.conf.d/d.conf": "/usr/local/lib\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := readLdSoConf(filepath.Join(dir, "ld.so.conf"))
+	// b.conf includes ld.so.conf again, and d.conf repeats a
+	// directory; both are only listed once.
+	want := []string{"/usr/local/lib", "/lib/x86_64-linux-gnu", "/usr/lib/x86_64-linux-gnu", "/opt/lib"}
+	if !slices.Equal(got, want) {
+		t.Errorf("readLdSoConf() = %v, want %v", got, want)
+	}
+}
+
+func TestExpandDST(t *testing.T) {
+	r := &libResolver{class: elf.ELFCLASS64, machine: elf.EM_X86_64}
+	got := r.expandDST([]string{"$ORIGIN/../lib:/opt/lib", "${ORIGIN}", "/opt/$PLATFORM/${LIB}"}, "/app/bin")
+	want := []string{
+		"/app/bin/../lib",
+		"/opt/lib",
+		"/app/bin",
+		"/opt/x86_64/lib",
+		"/opt/x86_64/lib64",
+		"/opt/x86_64/lib/x86_64-linux-gnu",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("expandDST() = %v, want %v", got, want)
+	}
+}
+
+func TestSearchPath(t *testing.T) {
+	t.Setenv("LD_LIBRARY_PATH", "/env")
+	r := &libResolver{class: elf.ELFCLASS64, machine: elf.EM_X86_64, ldConfDirs: []string{"/conf"}}
+	for _, tc := range []struct {
+		name           string
+		rpath, runpath []string
+		want           []string
+	}{
+		{
+			name:  "RPATH",
+			rpath: []string{"/rpath", "/inherited"},
+			want:  []string{"/rpath", "/inherited", "/env", "/conf", "/lib64", "/usr/lib64", "/lib", "/usr/lib"},
+		},
+		{
+			name:    "RUNPATH",
+			rpath:   []string{"/inherited"},
+			runpath: []string{"/runpath"},
+			want:    []string{"/env", "/runpath", "/conf", "/lib64", "/usr/lib64", "/lib", "/usr/lib"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := r.searchPath(tc.rpath, tc.runpath)
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("searchPath(%v, %v) = %v, want %v", tc.rpath, tc.runpath, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFindHwcaps(t *testing.T) {
+	path, _ := dynamicExecutable(t)
+	exe, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := elf.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &libResolver{class: f.Class, machine: f.Machine}
+	f.Close()
+
+	// Any ELF file of the right class and machine will do as a
+	// library here.
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty")
+	lib := filepath.Join(dir, "lib")
+	variant := filepath.Join(lib, "glibc-hwcaps", "x86-64-v3")
+	for _, d := range []string{empty, variant} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, p := range []string{filepath.Join(lib, "libfoo.so.1"), filepath.Join(variant, "libfoo.so.1")} {
+		if err := os.WriteFile(p, exe, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := r.find("libfoo.so.1", []string{empty, lib})
+	if err != nil {
+		t.Fatalf("find(): %v", err)
+	}
+	want := []string{filepath.Join(variant, "libfoo.so.1"), filepath.Join(lib, "libfoo.so.1")}
+	if !slices.Equal(got, want) {
+		t.Errorf("find() = %v, want %v", got, want)
+	}
+}
+
+// dynamicExecutable returns the path of a dynamically linked system
+// executable and its dynamic linker, or skips the test.
+func dynamicExecutable(t *testing.T) (path, interp string) {
+	t.Helper()
+	for _, path := range []string{"/usr/bin/true", "/bin/true"} {
+		f, err := elf.Open(path)
+		if err != nil {
+			continue
+		}
+		interp, err := elfInterp(f)
+		f.Close()
+		if err == nil && interp != "" {
+			return path, interp
+		}
+	}
+	t.Skip("no dynamically linked executable found")
+	return "", ""
+}
+
+func TestExecutableDeps(t *testing.T) {
+	path, interp := dynamicExecutable(t)
+
+	rule, err := ExecutableDeps(path)
+	if err != nil {
+		t.Fatalf("ExecutableDeps(%q): %v", path, err)
+	}
+	if !slices.Contains(rule.paths, path) || !slices.Contains(rule.paths, interp) {
+		t.Errorf("ExecutableDeps(%q) paths = %v, want %q and %q", path, rule.paths, path, interp)
+	}
+	if _, err := os.Stat(ldSoCachePath); err == nil && !slices.Contains(rule.paths, ldSoCachePath) {
+		t.Errorf("ExecutableDeps(%q) paths = %v, want %q", path, rule.paths, ldSoCachePath)
+	}
+	libs := slices.DeleteFunc(slices.Clone(rule.paths), func(p string) bool {
+		return p == path || p == interp || p == ldSoCachePath
+	})
+	if len(libs) == 0 {
+		t.Errorf("ExecutableDeps(%q) paths = %v, want at least one library", path, rule.paths)
+	}
+	if rule.accessFS != accessFSRead&accessFile {
+		t.Errorf("ExecutableDeps(%q) access = %v, want %v", path, rule.accessFS, accessFSRead&accessFile)
+	}
+}
+
+func TestExecutableDepsErrors(t *testing.T) {
+	notELF := filepath.Join(t.TempDir(), "script")
+	if err := os.WriteFile(notELF, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{notELF, filepath.Join(t.TempDir(), "missing")} {
+		if _, err := ExecutableDeps(path); err == nil {
+			t.Errorf("ExecutableDeps(%q) succeeded, want error", path)
+		}
+	}
+}
+
+func TestExecutableDepsEnforced(t *testing.T) {
+	lltest.RunInSubprocess(t, func() {
+		lltest.RequireABI(t, 1)
+		path, _ := dynamicExecutable(t)
+
+		rule, err := ExecutableDeps(path)
+		if err != nil {
+			t.Fatalf("ExecutableDeps(%q): %v", path, err)
+		}
+		// exec.Command connects stdin to /dev/null.
+		if err := V1.RestrictPaths(rule, RWFiles("/dev/null")); err != nil {
+			t.Fatalf("RestrictPaths(): %v", err)
+		}
+		if err := exec.Command(path).Run(); err != nil {
+			t.Errorf("Running %q: %v", path, err)
+		}
+		if _, err := os.ReadFile("/etc/passwd"); err == nil {
+			t.Errorf("Reading /etc/passwd succeeded, want error")
+		}
+	})
+}
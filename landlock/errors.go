@@ -0,0 +1,144 @@
+package landlock
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+)
+
+// ErrMaxStackedRulesets is returned when a ruleset can not be
+// enforced because the maximum number of stacked Landlock rulesets
+// is reached for the current thread.
+var ErrMaxStackedRulesets = errors.New("the maximum number of stacked rulesets is reached for the current thread")
+
+// UnsupportedABIError is returned when the running kernel does not
+// support the Landlock ABI that a (non-best-effort) [Config]
+// requires.
+type UnsupportedABIError struct {
+	// Have is the Landlock ABI version supported by the kernel.
+	// It is 0 if Landlock is not supported at all.
+	Have int
+
+	// Want is the configuration which could not be enforced.
+	Want Config
+}
+
+func (e *UnsupportedABIError) Error() string {
+	return fmt.Sprintf("missing kernel Landlock support. Got Landlock ABI v%v, wanted %v", e.Have, e.Want)
+}
+
+// IncompatibleRuleError is returned when a rule is incompatible with
+// the [Config] it is used with, for example because it grants access
+// rights which the Config does not handle.
+//
+// IncompatibleRuleError matches [syscall.EINVAL] when using
+// [errors.Is].
+type IncompatibleRuleError struct {
+	Rule Rule
+}
+
+func (e *IncompatibleRuleError) Error() string {
+	return fmt.Sprintf("incompatible rule %v: %v", e.Rule, syscall.EINVAL)
+}
+
+func (e *IncompatibleRuleError) Unwrap() error {
+	return syscall.EINVAL
+}
+
+// PathRuleError is returned when a filesystem rule can not be added
+// to the ruleset for one of its paths, for example because the path
+// does not exist.
+type PathRuleError struct {
+	// Path is the path that the error occurred for.
+	Path string
+
+	// Access is the set of access rights which should have been
+	// granted for Path.
+	Access AccessFSSet
+
+	// Errno is the error number returned by the failing system
+	// call, or 0 if the error did not originate from a system call.
+	Errno syscall.Errno
+
+	// Err is the underlying error.
+	Err error
+}
+
+func newPathRuleError(path string, access AccessFSSet, err error) *PathRuleError {
+	e := &PathRuleError{Path: path, Access: access, Err: err}
+	errors.As(err, &e.Errno)
+	return e
+}
+
+func (e *PathRuleError) Error() string {
+	return fmt.Sprintf("populating ruleset for %q with access %v: %v", e.Path, e.Access, e.Err)
+}
+
+func (e *PathRuleError) Unwrap() error {
+	return e.Err
+}
+
+// AccessDeniedError is returned by [Policy.Check] and the related
+// methods when a policy does not permit an operation.
+//
+// AccessDeniedError matches the error number which the kernel would
+// return for the operation when using [errors.Is].
+type AccessDeniedError struct {
+	// Path is the path on which the filesystem access rights in
+	// AccessFS are missing.
+	Path     string
+	AccessFS AccessFSSet
+
+	// Port is the TCP port on which the network access rights in
+	// AccessNet are missing.
+	Port      uint16
+	AccessNet AccessNetSet
+
+	// Reason optionally explains why the access rights are
+	// missing.
+	Reason string
+
+	// Errno is the error number returned by the kernel, usually
+	// EACCES.  Linking or renaming files between directories fails
+	// with EXDEV instead.
+	Errno syscall.Errno
+}
+
+func (e *AccessDeniedError) Error() string {
+	var msg string
+	if e.AccessNet != 0 {
+		msg = fmt.Sprintf("access %v denied on TCP port %v", e.AccessNet, e.Port)
+	} else {
+		msg = fmt.Sprintf("access %v denied on %q", e.AccessFS, e.Path)
+	}
+	if e.Reason != "" {
+		msg += " (" + e.Reason + ")"
+	}
+	return msg
+}
+
+func (e *AccessDeniedError) Unwrap() error {
+	return e.Errno
+}
+
+// BugError denotes an error that should not have happened.
+//
+// If such an error occurs anyway, please try upgrading the library
+// and file a bug to github.com/landlock-lsm/go-landlock if the issue
+// persists.
+type BugError struct {
+	Err error
+}
+
+func (e *BugError) Error() string {
+	return fmt.Sprintf("BUG(go-landlock): This should not have happened: %v", e.Err)
+}
+
+func (e *BugError) Unwrap() error {
+	return e.Err
+}
+
+// bug wraps err as a *BugError.
+func bug(err error) error {
+	return &BugError{Err: err}
+}
@@ -0,0 +1,55 @@
+package landlock
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+	"testing"
+)
+
+func TestErrorMessages(t *testing.T) {
+	for _, tt := range []struct {
+		err  error
+		want string
+	}{
+		{
+			err:  &UnsupportedABIError{Have: 3, Want: V5},
+			want: "missing kernel Landlock support. Got Landlock ABI v3, wanted {Landlock V5; FS: all; Net: all; Scoped: ∅}",
+		},
+		{
+			err:  &IncompatibleRuleError{Rule: ConnectTCP(53)},
+			want: "incompatible rule ALLOW {connect_tcp} on TCP port 53: invalid argument",
+		},
+		{
+			err:  newPathRuleError("/foo", accessFSRead, fmt.Errorf("open: %w", syscall.ENOENT)),
+			want: `populating ruleset for "/foo" with access {execute,read_file,read_dir}: open: no such file or directory`,
+		},
+		{
+			err:  bug(errors.New("oops")),
+			want: "BUG(go-landlock): This should not have happened: oops",
+		},
+	} {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("Error() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestErrorUnwrapping(t *testing.T) {
+	if err := error(&IncompatibleRuleError{Rule: RODirs("/")}); !errors.Is(err, syscall.EINVAL) {
+		t.Errorf("errors.Is(%v, EINVAL) = false, want true", err)
+	}
+
+	pathErr := newPathRuleError("/foo", accessFSRead, fmt.Errorf("open: %w", syscall.ENOENT))
+	if pathErr.Errno != syscall.ENOENT {
+		t.Errorf("pathErr.Errno = %v, want ENOENT", pathErr.Errno)
+	}
+	if !errors.Is(pathErr, syscall.ENOENT) {
+		t.Errorf("errors.Is(%v, ENOENT) = false, want true", pathErr)
+	}
+
+	wrapped := fmt.Errorf("%w: %w", ErrMaxStackedRulesets, syscall.E2BIG)
+	if !errors.Is(wrapped, ErrMaxStackedRulesets) || !errors.Is(wrapped, syscall.E2BIG) {
+		t.Errorf("%v does not match both ErrMaxStackedRulesets and E2BIG", wrapped)
+	}
+}
@@ -0,0 +1,333 @@
+package landlock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Report describes the Landlock ruleset which would be enforced on
+// the running kernel for a given configuration and set of rules.
+// It is returned by [Config.Explain].
+type Report struct {
+	// ABI is the Landlock ABI version supported by the running
+	// kernel, as used by Go-Landlock.  It is 0 if Landlock is not
+	// available.
+	ABI int
+
+	// Requested is the configuration which was passed to Explain.
+	Requested Config
+
+	// Effective is the configuration which would be enforced,
+	// after downgrading it in best effort mode.
+	Effective Config
+
+	// The access rights, scopes and restriction flags which are
+	// handled by Requested, but which were dropped from Effective
+	// in best effort mode.
+	DroppedAccessFS  AccessFSSet
+	DroppedAccessNet AccessNetSet
+	DroppedScoped    ScopedSet
+	DroppedFlags     []string
+
+	// FellBackToV0 is true if best effort mode had to give up on
+	// enforcing Landlock entirely, because a rule asks for the
+	// "refer" access right, which is not available on the
+	// running kernel.
+	FellBackToV0 bool
+
+	// Noop is true if enforcing the ruleset would not restrict
+	// anything.
+	Noop bool
+
+	// Rules has an entry for each individual rule, with composite
+	// rules expanded into their sub-rules and dynamic rules resolved.
+	// It also includes the rules which Go-Landlock adds implicitly.
+	Rules []RuleReport
+}
+
+// RuleReport describes the effect of a single rule in a [Report].
+type RuleReport struct {
+	// Rule is the rule as it was passed to Explain, or as it was
+	// returned by a [DynamicRule].
+	Rule Rule
+
+	// Implicit is true if the rule was not passed to Explain, but is
+	// added by Go-Landlock, e.g. to work around the libpsx issue
+	// https://github.com/landlock-lsm/go-landlock/issues/39 on
+	// kernels before Landlock ABI V8.
+	Implicit bool
+
+	// For filesystem rules, the access rights which the rule asks
+	// for, which it effectively grants, and which were dropped in
+	// best effort mode.
+	RequestedAccessFS AccessFSSet
+	EffectiveAccessFS AccessFSSet
+	DroppedAccessFS   AccessFSSet
+
+	// MissingPaths are the paths which do not exist and which are
+	// ignored because of [FSRule.IgnoreIfMissing].
+	MissingPaths []string
+
+	// Expansions describe what the rule's paths expanded to, for
+	// rules which use [FSRule.Expand].
+	Expansions []PathExpansion
+
+	// Targets describe the files which the rule's paths and open
+	// files resolve to.
+	Targets []PathTarget
+
+	// For network rules, the access rights which the rule asks
+	// for, which it effectively grants, and which were dropped in
+	// best effort mode.
+	RequestedAccessNet AccessNetSet
+	EffectiveAccessNet AccessNetSet
+	DroppedAccessNet   AccessNetSet
+}
+
+// PathTarget describes which file a path in a filesystem rule
+// resolves to, and therefore which file hierarchy the rule grants
+// access to.
+type PathTarget struct {
+	// Path is the path as given in the rule.  For rules created
+	// with [FDAccess], it is the name of the open file.
+	Path string
+
+	// Target is the canonical path of the file which the rule
+	// grants access to.  It is empty if the path can not be
+	// determined, e.g. because /proc is not mounted.
+	Target string
+
+	// Outside is true if Target is neither Path nor beneath it,
+	// for example because Path is a symlink to an unrelated
+	// directory.  Symlinks in the parent directories of Path are
+	// resolved before the comparison, so that paths like
+	// /lib/x86_64-linux-gnu, where /lib is a symlink to /usr/lib,
+	// are not flagged.
+	Outside bool
+}
+
+func newPathTarget(path, target string) PathTarget {
+	expected := path
+	if abs, err := filepath.Abs(path); err == nil {
+		expected = abs
+	}
+	if dir, err := filepath.EvalSymlinks(filepath.Dir(expected)); err == nil {
+		expected = filepath.Join(dir, filepath.Base(expected))
+	}
+	return PathTarget{
+		Path:    path,
+		Target:  target,
+		Outside: target != "" && !isBeneath(target, expected),
+	}
+}
+
+// Explain calculates which ruleset [Config.Restrict] would enforce on
+// the running kernel, without enforcing anything.
+//
+// Explain runs the same compatibility checks and best effort
+// downgrades as [Config.Restrict], and returns the same errors for
+// incompatible rules, missing kernel support and missing paths.
+//
+// Explain also resolves the paths of filesystem rules like the
+// enforcement would, and reports the files they resolve to in
+// [RuleReport.Targets].  Paths which resolve to a file outside of the
+// given path (e.g. through a symlink) are flagged, so that a rule
+// like RWDirs("/var/app") can not silently grant access to "/".  If
+// /proc is not available, the targets are reported as unknown.
+//
+// This is useful for reviewing what a given host is going to enforce,
+// in particular when [Config.BestEffort] is used.
+func (c Config) Explain(rules ...Rule) (*Report, error) {
+	return explain(c, rules, getSupportedABIVersion())
+}
+
+func explain(c Config, rules []Rule, abi abiInfo) (*Report, error) {
+	resolveCfg := c
+	if c.bestEffort {
+		resolveCfg = c.restrictTo(abi)
+	}
+	rules, err := resolveDynamicRules(resolveCfg, rules)
+	if err != nil {
+		return nil, err
+	}
+	implicit := implicitRules(c, abi)
+	rules = slices.Concat(rules, implicit)
+	for _, rule := range rules {
+		if !rule.compatibleWithConfig(c) {
+			return nil, &IncompatibleRuleError{Rule: rule}
+		}
+	}
+
+	rep := &Report{
+		ABI:       abi.version,
+		Requested: c,
+		Effective: c,
+	}
+	leaves := flattenRules(rules)
+	effective := make([]Rule, len(leaves))
+	copy(effective, leaves)
+
+	if c.bestEffort {
+		eff := c.restrictTo(abi)
+		for i, rule := range leaves {
+			r, ok := rule.downgrade(eff)
+			if !ok {
+				eff = v0
+				rep.FellBackToV0 = true
+				break
+			}
+			effective[i] = r
+		}
+		ev := newDegradeEvent(c, eff, abi, rep.FellBackToV0)
+		rep.Effective = eff
+		rep.DroppedAccessFS = ev.DroppedAccessFS
+		rep.DroppedAccessNet = ev.DroppedAccessNet
+		rep.DroppedScoped = ev.DroppedScoped
+		rep.DroppedFlags = ev.DroppedFlags
+	}
+	if !rep.Effective.compatibleWithABI(abi) {
+		return nil, &UnsupportedABIError{Have: abi.version, Want: rep.Effective}
+	}
+
+	eff := rep.Effective
+	rep.Noop = eff.handledAccessFS.isEmpty() && eff.handledAccessNet.isEmpty() && eff.scoped.isEmpty()
+
+	firstImplicit := len(leaves) - len(flattenRules(implicit))
+	for i, rule := range leaves {
+		rr := RuleReport{Rule: rule, Implicit: i >= firstImplicit}
+		switch r := rule.(type) {
+		case FSRule:
+			rr.RequestedAccessFS = r.accessFS
+			if !rep.FellBackToV0 {
+				rr.EffectiveAccessFS = effective[i].(FSRule).effectiveAccess(eff)
+			}
+			rr.DroppedAccessFS = rr.RequestedAccessFS &^ rr.EffectiveAccessFS
+			if rep.Noop || rr.EffectiveAccessFS.isEmpty() {
+				break // Paths are not opened in that case.
+			}
+			paths := r.paths
+			if r.expand {
+				rr.Expansions, err = r.expansions(rr.EffectiveAccessFS)
+				if err != nil {
+					return nil, err
+				}
+				paths = nil
+				for _, e := range rr.Expansions {
+					if len(e.Paths) == 0 {
+						rr.MissingPaths = append(rr.MissingPaths, e.Pattern)
+					}
+					paths = append(paths, e.Paths...)
+				}
+			}
+			for _, path := range paths {
+				target, err := r.resolveTarget(path)
+				if r.ignoreMissing && errors.Is(err, os.ErrNotExist) {
+					rr.MissingPaths = append(rr.MissingPaths, path)
+					continue
+				}
+				if err != nil {
+					return nil, newPathRuleError(path, rr.EffectiveAccessFS, err)
+				}
+				if r.root != nil {
+					path = filepath.Join(r.root.Name(), path)
+				}
+				rr.Targets = append(rr.Targets, newPathTarget(path, target))
+			}
+			for _, f := range r.files {
+				target, err := fileTarget(f)
+				if err != nil {
+					return nil, newPathRuleError(f.Name(), rr.EffectiveAccessFS, err)
+				}
+				rr.Targets = append(rr.Targets, newPathTarget(f.Name(), target))
+			}
+		case NetRule:
+			rr.RequestedAccessNet = r.access
+			if !rep.FellBackToV0 {
+				rr.EffectiveAccessNet = effective[i].(NetRule).access
+			}
+			rr.DroppedAccessNet = rr.RequestedAccessNet &^ rr.EffectiveAccessNet
+		}
+		rep.Rules = append(rep.Rules, rr)
+	}
+	return rep, nil
+}
+
+// implicitRules returns the rules which Go-Landlock adds to the given
+// rules when restricting the process with c on the given ABI.
+func implicitRules(c Config, abi abiInfo) []Rule {
+	if abi.version < 8 {
+		// Work around https://github.com/landlock-lsm/go-landlock/issues/39
+		return maybeWorkaroundBug39(c, nil)
+	}
+	return nil
+}
+
+// String returns a human-readable multi-line description of the report.
+func (r *Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Kernel: Landlock ABI V%d\n", r.ABI)
+	fmt.Fprintf(&b, "Requested: %v\n", r.Requested)
+	fmt.Fprintf(&b, "Effective: %v\n", r.Effective)
+	if r.FellBackToV0 {
+		b.WriteString("WARNING: Fell back to no enforcement (\"refer\" is not supported by the kernel)\n")
+	} else if r.Noop {
+		b.WriteString("WARNING: Enforcement is a no-op\n")
+	}
+	if !r.DroppedAccessFS.isEmpty() {
+		fmt.Fprintf(&b, "Dropped FS access rights: %v\n", r.DroppedAccessFS)
+	}
+	if !r.DroppedAccessNet.isEmpty() {
+		fmt.Fprintf(&b, "Dropped network access rights: %v\n", r.DroppedAccessNet)
+	}
+	if !r.DroppedScoped.isEmpty() {
+		fmt.Fprintf(&b, "Dropped scopes: %v\n", r.DroppedScoped)
+	}
+	if len(r.DroppedFlags) > 0 {
+		fmt.Fprintf(&b, "Dropped flags: %v\n", strings.Join(r.DroppedFlags, ","))
+	}
+	for _, rr := range r.Rules {
+		if rr.Implicit {
+			fmt.Fprintf(&b, "Implicit rule: %v\n", rr)
+		} else {
+			fmt.Fprintf(&b, "Rule: %v\n", rr)
+		}
+		for _, e := range rr.Expansions {
+			if len(e.Paths) != 1 || e.Paths[0] != e.Pattern {
+				fmt.Fprintf(&b, "Expanded %q to %q\n", e.Pattern, e.Paths)
+			}
+		}
+		for _, pt := range rr.Targets {
+			if pt.Outside {
+				fmt.Fprintf(&b, "WARNING: %q resolves to %q, outside of the given path\n", pt.Path, pt.Target)
+			}
+		}
+	}
+	return b.String()
+}
+
+// String returns a human-readable description of the rule report.
+func (rr RuleReport) String() string {
+	var b strings.Builder
+	switch r := rr.Rule.(type) {
+	case FSRule:
+		fmt.Fprintf(&b, "paths %v: effective %v", r.names(), rr.EffectiveAccessFS)
+		if !rr.DroppedAccessFS.isEmpty() {
+			fmt.Fprintf(&b, ", dropped %v", rr.DroppedAccessFS)
+		}
+		if len(rr.MissingPaths) > 0 {
+			fmt.Fprintf(&b, ", ignored missing paths %v", rr.MissingPaths)
+		}
+	case NetRule:
+		fmt.Fprintf(&b, "TCP port %v: effective %v", r.portsString(), rr.EffectiveAccessNet)
+		if !rr.DroppedAccessNet.isEmpty() {
+			fmt.Fprintf(&b, ", dropped %v", rr.DroppedAccessNet)
+		}
+	default:
+		fmt.Fprintf(&b, "%v", rr.Rule)
+	}
+	return b.String()
+}
@@ -0,0 +1,36 @@
+//go:build linux
+
+package landlock
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestExplainTargetsWithoutProc(t *testing.T) {
+	old := procSelfFD
+	procSelfFD = filepath.Join(t.TempDir(), "missing")
+	t.Cleanup(func() { procSelfFD = old })
+
+	dir := t.TempDir()
+	f, err := os.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	rep, err := explain(V1, []Rule{RODirs(dir), FDAccess(accessFSRead, f)}, abiInfos[1])
+	if err != nil {
+		t.Fatalf("explain(): %v", err)
+	}
+	want := []PathTarget{{Path: dir}, {Path: f.Name()}}
+	var got []PathTarget
+	for _, rr := range explicitRuleReports(rep) {
+		got = append(got, rr.Targets...)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Targets = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,258 @@
+package landlock
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	ll "github.com/landlock-lsm/go-landlock/landlock/syscall"
+)
+
+func TestExplain(t *testing.T) {
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "missing")
+
+	for _, tt := range []struct {
+		name         string
+		cfg          Config
+		rules        []Rule
+		abi          int
+		wantEff      Config
+		wantDropFS   AccessFSSet
+		wantDropNet  AccessNetSet
+		wantDropScop ScopedSet
+		wantFlags    []string
+		wantV0       bool
+		wantNoop     bool
+		wantRules    []RuleReport
+	}{
+		{
+			name:    "NoDowngrade",
+			cfg:     V3,
+			rules:   []Rule{RODirs(dir)},
+			abi:     3,
+			wantEff: V3,
+			wantRules: []RuleReport{
+				{RequestedAccessFS: accessFSRead, EffectiveAccessFS: accessFSRead},
+			},
+		},
+		{
+			name: "BestEffortDowngrade",
+			cfg:  V9.BestEffort().EnableLoggingForSubprocesses(),
+			rules: []Rule{
+				CompositeRule(
+					RWDirs(dir).WithIoctlDev(),
+					RODirs(missing).IgnoreIfMissing(),
+				),
+				ConnectTCP(53),
+			},
+			abi:          3,
+			wantEff:      V3.BestEffort(),
+			wantDropFS:   V9.handledAccessFS &^ V3.handledAccessFS,
+			wantDropNet:  V9.handledAccessNet,
+			wantDropScop: V9.scoped,
+			wantFlags:    []string{"log_new_exec_on"},
+			wantRules: []RuleReport{
+				{
+					RequestedAccessFS: accessFSReadWrite | ll.AccessFSIoctlDev,
+					EffectiveAccessFS: accessFSReadWrite,
+					DroppedAccessFS:   ll.AccessFSIoctlDev,
+				},
+				{
+					RequestedAccessFS: accessFSRead,
+					EffectiveAccessFS: accessFSRead,
+					MissingPaths:      []string{missing},
+				},
+				{
+					RequestedAccessNet: ll.AccessNetConnectTCP,
+					DroppedAccessNet:   ll.AccessNetConnectTCP,
+				},
+			},
+		},
+		{
+			name:       "ReferFallsBackToV0",
+			cfg:        V2.BestEffort(),
+			rules:      []Rule{RWDirs(dir).WithRefer()},
+			abi:        1,
+			wantEff:    v0,
+			wantDropFS: V2.handledAccessFS,
+			wantV0:     true,
+			wantNoop:   true,
+			wantRules: []RuleReport{
+				{
+					RequestedAccessFS: accessFSReadWrite | ll.AccessFSRefer,
+					DroppedAccessFS:   accessFSReadWrite | ll.AccessFSRefer,
+				},
+			},
+		},
+		{
+			name:       "NoLandlock",
+			cfg:        V1.BestEffort(),
+			rules:      []Rule{RODirs(missing)},
+			abi:        0,
+			wantEff:    abiInfos[0].asConfig().BestEffort(),
+			wantDropFS: V1.handledAccessFS,
+			wantNoop:   true,
+			wantRules: []RuleReport{
+				{RequestedAccessFS: accessFSRead, DroppedAccessFS: accessFSRead},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			rep, err := explain(tt.cfg, tt.rules, abiInfos[tt.abi])
+			if err != nil {
+				t.Fatalf("explain(): %v", err)
+			}
+			if rep.ABI != tt.abi {
+				t.Errorf("ABI = %v, want %v", rep.ABI, tt.abi)
+			}
+			if rep.Effective != tt.wantEff {
+				t.Errorf("Effective = %v, want %v", rep.Effective, tt.wantEff)
+			}
+			if rep.DroppedAccessFS != tt.wantDropFS {
+				t.Errorf("DroppedAccessFS = %v, want %v", rep.DroppedAccessFS, tt.wantDropFS)
+			}
+			if rep.DroppedAccessNet != tt.wantDropNet {
+				t.Errorf("DroppedAccessNet = %v, want %v", rep.DroppedAccessNet, tt.wantDropNet)
+			}
+			if rep.DroppedScoped != tt.wantDropScop {
+				t.Errorf("DroppedScoped = %v, want %v", rep.DroppedScoped, tt.wantDropScop)
+			}
+			if !slices.Equal(rep.DroppedFlags, tt.wantFlags) {
+				t.Errorf("DroppedFlags = %v, want %v", rep.DroppedFlags, tt.wantFlags)
+			}
+			if rep.FellBackToV0 != tt.wantV0 {
+				t.Errorf("FellBackToV0 = %v, want %v", rep.FellBackToV0, tt.wantV0)
+			}
+			if rep.Noop != tt.wantNoop {
+				t.Errorf("Noop = %v, want %v", rep.Noop, tt.wantNoop)
+			}
+			rules := explicitRuleReports(rep)
+			if len(rules) != len(tt.wantRules) {
+				t.Fatalf("got %d rule reports, want %d", len(rules), len(tt.wantRules))
+			}
+			for i, got := range rules {
+				want := tt.wantRules[i]
+				if got.RequestedAccessFS != want.RequestedAccessFS ||
+					got.EffectiveAccessFS != want.EffectiveAccessFS ||
+					got.DroppedAccessFS != want.DroppedAccessFS ||
+					!slices.Equal(got.MissingPaths, want.MissingPaths) ||
+					got.RequestedAccessNet != want.RequestedAccessNet ||
+					got.EffectiveAccessNet != want.EffectiveAccessNet ||
+					got.DroppedAccessNet != want.DroppedAccessNet {
+					t.Errorf("Rules[%d] = %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+// explicitRuleReports returns the reports of the rules which were
+// passed to Explain, without the implicit ones, which depend on the
+// build configuration.
+func explicitRuleReports(rep *Report) []RuleReport {
+	return slices.DeleteFunc(slices.Clone(rep.Rules), func(rr RuleReport) bool {
+		return rr.Implicit
+	})
+}
+
+func TestExplainImplicitRules(t *testing.T) {
+	for _, abi := range []int{3, 8} {
+		rep, err := explain(V3, []Rule{RODirs("/")}, abiInfos[abi])
+		if err != nil {
+			t.Fatalf("explain(): %v", err)
+		}
+		var implicit []Rule
+		for i, rr := range rep.Rules {
+			if rr.Implicit {
+				if i == 0 {
+					t.Errorf("V%d: the explicit rule is reported as implicit", abi)
+				}
+				implicit = append(implicit, rr.Rule)
+			}
+		}
+		// The rules which prepare adds on the same ABI.
+		want := implicitRules(V3, abiInfos[abi])
+		if len(implicit) != len(want) {
+			t.Errorf("V%d: implicit rules = %v, want %v", abi, implicit, want)
+		}
+		if abi >= 8 && len(implicit) > 0 {
+			t.Errorf("V%d: implicit rules = %v, want none", abi, implicit)
+		}
+	}
+}
+
+func TestExplainErrors(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing")
+
+	if _, err := explain(V3, []Rule{RODirs(missing)}, abiInfos[3]); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("explain() with missing path: got %v, want 'not exist' error", err)
+	}
+	if _, err := explain(V3, []Rule{RODirs("/")}, abiInfos[2]); err == nil {
+		t.Errorf("explain() of V3 on V2 kernel succeeded, want error")
+	}
+	if _, err := explain(V1, []Rule{RWDirs("/").WithRefer()}, abiInfos[1]); err == nil {
+		t.Errorf("explain() with refer on V1 succeeded, want error")
+	}
+}
+
+func TestExplainTargets(t *testing.T) {
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "sub")
+	other := filepath.Join(dir, "other")
+	for _, d := range []string{sub, other} {
+		if err := os.Mkdir(d, 0o700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	inside := filepath.Join(sub, "inside")
+	outside := filepath.Join(sub, "outside")
+	if err := os.Symlink(".", inside); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(other, outside); err != nil {
+		t.Fatal(err)
+	}
+	// Canonicalize in case the temporary directory is a symlink.
+	sub, err := filepath.EvalSymlinks(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err = filepath.EvalSymlinks(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A symlink in a parent directory, like /lib -> /usr/lib on
+	// merged /usr systems, does not make a path outside.
+	if err := os.Mkdir(filepath.Join(sub, "child"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(sub, filepath.Join(dir, "alias")); err != nil {
+		t.Fatal(err)
+	}
+	viaAlias := filepath.Join(dir, "alias", "child")
+
+	rep, err := explain(V1, []Rule{RWDirs(filepath.Join(dir, "sub"), outside, viaAlias)}, abiInfos[1])
+	if err != nil {
+		t.Fatalf("explain(): %v", err)
+	}
+	want := []PathTarget{
+		{Path: filepath.Join(dir, "sub"), Target: sub},
+		{Path: outside, Target: other, Outside: true},
+		{Path: viaAlias, Target: filepath.Join(sub, "child")},
+	}
+	if got := rep.Rules[0].Targets; !slices.Equal(got, want) {
+		t.Errorf("Targets = %+v, want %+v", got, want)
+	}
+	if s := rep.String(); !strings.Contains(s, "WARNING: \""+outside+"\" resolves to \""+other+"\"") {
+		t.Errorf("String() = %q, want warning about %q", s, outside)
+	}
+
+	if _, err := explain(V1, []Rule{RODirs(outside).NoFollow()}, abiInfos[1]); err == nil {
+		t.Errorf("explain() with NoFollow on a symlink succeeded, want error")
+	}
+}
@@ -59,6 +59,56 @@
 //	    landlock.ConnectTCP(53),
 //	)
 //
+// # Preparing a ruleset ahead of time
+//
+// The [Config.Prepare] method resolves all rules and builds the
+// kernel ruleset without enforcing it.  The returned [Ruleset] can be
+// enforced at a later point:
+//
+//	rs, err := landlock.V9.BestEffort().Prepare(
+//	    landlock.RODirs("/usr", "/bin"),
+//	)
+//	if err != nil {
+//	    log.Fatalf("invalid sandbox configuration: %v", err)
+//	}
+//	defer rs.Close()
+//
+//	// ... later ...
+//
+//	err = rs.Enforce()
+//
+// # Sandboxing child processes
+//
+// The [Config.ApplyToCmd] method restricts a child process started
+// through [os/exec], while leaving the calling process unrestricted.
+// The program needs to call [RunExecHelper] at the start of its main
+// function for this:
+//
+//	cmd := exec.Command("/usr/bin/cc", "-c", "main.c")
+//	err := landlock.V9.BestEffort().ApplyToCmd(cmd,
+//	    landlock.RODirs("/usr", "/lib", "/etc"),
+//	    landlock.RWDirs(buildDir),
+//	)
+//
+// For processing untrusted input in a pool of restricted worker
+// processes, see the [github.com/landlock-lsm/go-landlock/landlock/worker]
+// package.
+//
+// # Loading policies from files
+//
+// [LoadPolicy] reads a configuration and rules from a versioned policy
+// format in JSON, so that sandbox policies can be changed without
+// recompiling the program:
+//
+//	cfg, rules, err := landlock.LoadPolicy(f)
+//	if err != nil {
+//	    log.Fatalf("invalid policy: %v", err)
+//	}
+//	err = cfg.Restrict(rules...)
+//
+// The [github.com/landlock-lsm/go-landlock/landlock/policytoml] package
+// reads the same format in TOML.
+//
 // # More possible invocations
 //
 // landlock.V9.RestrictPaths(...) (without the call to
@@ -76,7 +126,9 @@
 // The situations in which audit logging happens can be configured
 // using [Config.DisableLoggingForOriginatingProcess],
 // [Config.EnableLoggingForSubprocesses] and
-// [Config.DisableLoggingForSubdomains].
+// [Config.DisableLoggingForSubdomains].  The resulting audit records
+// can be decoded with the [github.com/landlock-lsm/go-landlock/landlock/audit]
+// package.
 //
 // # Landlock ABI versioning
 //
@@ -2,6 +2,8 @@ package landlock
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 
 	ll "github.com/landlock-lsm/go-landlock/landlock/syscall"
 )
@@ -9,6 +11,7 @@ import (
 type NetRule struct {
 	access AccessNetSet
 	port   uint16
+	extra  uint16 // number of ports following port which are also included
 }
 
 // ConnectTCP is a [Rule] which grants the right to connect a socket
@@ -36,8 +39,124 @@ func BindTCP(port uint16) NetRule {
 	}
 }
 
+// ConnectTCPRange is a [Rule] which grants the right to connect a
+// socket to the TCP ports from lo to hi, inclusive.  The bounds may be
+// given in either order.
+func ConnectTCPRange(lo, hi uint16) NetRule {
+	return portRange(ll.AccessNetConnectTCP, lo, hi)
+}
+
+// BindTCPRange is a [Rule] which grants the right to bind a socket to
+// the TCP ports from lo to hi, inclusive.  The bounds may be given in
+// either order.
+func BindTCPRange(lo, hi uint16) NetRule {
+	return portRange(ll.AccessNetBindTCP, lo, hi)
+}
+
+// ConnectTCPPorts is a [Rule] which grants the right to connect a
+// socket to any of the given TCP ports.  Duplicate ports are ignored,
+// and consecutive ports are combined into port ranges.
+func ConnectTCPPorts(ports ...uint16) Rule {
+	return portSet(ll.AccessNetConnectTCP, ports)
+}
+
+// BindTCPPorts is a [Rule] which grants the right to bind a socket to
+// any of the given TCP ports.  Duplicate ports are ignored, and
+// consecutive ports are combined into port ranges.
+func BindTCPPorts(ports ...uint16) Rule {
+	return portSet(ll.AccessNetBindTCP, ports)
+}
+
+func portRange(access AccessNetSet, lo, hi uint16) NetRule {
+	if hi < lo {
+		lo, hi = hi, lo
+	}
+	return NetRule{
+		access: access,
+		port:   lo,
+		extra:  hi - lo,
+	}
+}
+
+func portSet(access AccessNetSet, ports []uint16) Rule {
+	ports = slices.Clone(ports)
+	slices.Sort(ports)
+	ports = slices.Compact(ports)
+
+	var rules []Rule
+	for i := 0; i < len(ports); {
+		j := i
+		for j+1 < len(ports) && ports[j+1] == ports[j]+1 {
+			j++
+		}
+		rules = append(rules, portRange(access, ports[i], ports[j]))
+		i = j + 1
+	}
+	if len(rules) == 1 {
+		return rules[0]
+	}
+	return CompositeRule(rules...)
+}
+
+// Access returns the network access rights which the rule grants.
+func (n NetRule) Access() AccessNetSet {
+	return n.access
+}
+
+// Port returns the TCP port to which the rule grants access.  For
+// rules which cover a port range, it is the first port of the range.
+func (n NetRule) Port() uint16 {
+	return n.port
+}
+
+// Ports returns the first and last TCP port to which the rule grants
+// access.  They are equal for rules which cover a single port.
+func (n NetRule) Ports() (lo, hi uint16) {
+	return n.port, n.lastPort()
+}
+
+// lastPort returns the last port in the port range of the rule.
+func (n NetRule) lastPort() uint16 {
+	return n.port + n.extra
+}
+
+// containsPort returns true if port is in the port range of the rule.
+func (n NetRule) containsPort(port uint16) bool {
+	return n.port <= port && port <= n.lastPort()
+}
+
+// portsString formats the port range of the rule, e.g. "80" or
+// "8000-8999".
+func (n NetRule) portsString() string {
+	if n.extra == 0 {
+		return fmt.Sprintf("%v", n.port)
+	}
+	return fmt.Sprintf("%v-%v", n.port, n.lastPort())
+}
+
 func (n NetRule) String() string {
-	return fmt.Sprintf("ALLOW %v on TCP port %v", n.access, n.port)
+	if n.extra == 0 {
+		return fmt.Sprintf("ALLOW %v on TCP port %v", n.access, n.port)
+	}
+	return fmt.Sprintf("ALLOW %v on TCP ports %v", n.access, n.portsString())
+}
+
+// netRulesString formats a list of network rules with the same access
+// rights compactly, e.g. "ALLOW {connect_tcp} on TCP ports 80,443".
+// ok is false if the rules are not all network rules with the same
+// access rights.
+func netRulesString(rules []Rule) (s string, ok bool) {
+	var ports []string
+	var access AccessNetSet
+	for i, rule := range rules {
+		n, ok := rule.(NetRule)
+		if !ok || (i > 0 && n.access != access) {
+			return "", false
+		}
+		access = n.access
+		ports = append(ports, n.portsString())
+	}
+	return fmt.Sprintf("ALLOW %v on TCP ports %v", access, strings.Join(ports, ",")), true
 }
 
 func (n NetRule) compatibleWithConfig(c Config) bool {
@@ -51,16 +170,19 @@ func (n NetRule) addToRuleset(rulesetFD int, c Config) error {
 		return nil
 	}
 	flags := 0
-	attr := &ll.NetPortAttr{
-		AllowedAccess: uint64(n.access),
-		Port:          uint64(n.port),
+	for port := int(n.port); port <= int(n.lastPort()); port++ {
+		attr := &ll.NetPortAttr{
+			AllowedAccess: uint64(n.access),
+			Port:          uint64(port),
+		}
+		if err := ll.LandlockAddNetPortRule(rulesetFD, attr, flags); err != nil {
+			return err
+		}
 	}
-	return ll.LandlockAddNetPortRule(rulesetFD, attr, flags)
+	return nil
 }
 
 func (n NetRule) downgrade(c Config) (out Rule, ok bool) {
-	return NetRule{
-		access: n.access.intersect(c.handledAccessNet),
-		port:   n.port,
-	}, true
+	n.access = n.access.intersect(c.handledAccessNet)
+	return n, true
 }
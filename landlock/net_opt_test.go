@@ -0,0 +1,109 @@
+package landlock
+
+import (
+	"reflect"
+	"testing"
+
+	ll "github.com/landlock-lsm/go-landlock/landlock/syscall"
+)
+
+func TestPortRuleConstructors(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		rule    Rule
+		want    Rule
+		wantStr string
+	}{
+		{
+			name:    "Single",
+			rule:    ConnectTCP(80),
+			want:    NetRule{access: ll.AccessNetConnectTCP, port: 80},
+			wantStr: "ALLOW {connect_tcp} on TCP port 80",
+		},
+		{
+			name:    "Range",
+			rule:    ConnectTCPRange(8000, 8999),
+			want:    NetRule{access: ll.AccessNetConnectTCP, port: 8000, extra: 999},
+			wantStr: "ALLOW {connect_tcp} on TCP ports 8000-8999",
+		},
+		{
+			name:    "RangeReversed",
+			rule:    BindTCPRange(65535, 0),
+			want:    NetRule{access: ll.AccessNetBindTCP, port: 0, extra: 65535},
+			wantStr: "ALLOW {bind_tcp} on TCP ports 0-65535",
+		},
+		{
+			name:    "RangeOfOne",
+			rule:    BindTCPRange(53, 53),
+			want:    BindTCP(53),
+			wantStr: "ALLOW {bind_tcp} on TCP port 53",
+		},
+		{
+			name:    "PortsSingleRange",
+			rule:    ConnectTCPPorts(12, 10, 11, 10),
+			want:    ConnectTCPRange(10, 12),
+			wantStr: "ALLOW {connect_tcp} on TCP ports 10-12",
+		},
+		{
+			name: "Ports",
+			rule: ConnectTCPPorts(443, 80, 8001, 8000, 80, 8002),
+			want: CompositeRule(
+				ConnectTCP(80),
+				ConnectTCP(443),
+				ConnectTCPRange(8000, 8002),
+			),
+			wantStr: "ALLOW {connect_tcp} on TCP ports 80,443,8000-8002",
+		},
+		{
+			name: "PortsAtEnd",
+			rule: BindTCPPorts(65535, 65534, 1),
+			want: CompositeRule(
+				BindTCP(1),
+				BindTCPRange(65534, 65535),
+			),
+			wantStr: "ALLOW {bind_tcp} on TCP ports 1,65534-65535",
+		},
+		{
+			name:    "MixedComposite",
+			rule:    CompositeRule(ConnectTCP(80), BindTCP(80)),
+			want:    CompositeRule(ConnectTCP(80), BindTCP(80)),
+			wantStr: "{ALLOW {connect_tcp} on TCP port 80; ALLOW {bind_tcp} on TCP port 80}",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.rule, tt.want) {
+				t.Errorf("rule = %#v, want %#v", tt.rule, tt.want)
+			}
+			if got := tt.rule.(interface{ String() string }).String(); got != tt.wantStr {
+				t.Errorf("String() = %q, want %q", got, tt.wantStr)
+			}
+		})
+	}
+}
+
+func TestPortRangeDowngrade(t *testing.T) {
+	r, ok := ConnectTCPRange(1000, 2000).downgrade(V3)
+	if !ok {
+		t.Fatalf("downgrade() failed")
+	}
+	want := NetRule{access: 0, port: 1000, extra: 1000}
+	if r != want {
+		t.Errorf("downgrade() = %#v, want %#v", r, want)
+	}
+}
+
+func TestNetRuleAccessors(t *testing.T) {
+	r := BindTCPRange(8010, 8000)
+	if got, want := r.Access(), AccessNetSet(ll.AccessNetBindTCP); got != want {
+		t.Errorf("Access() = %v, want %v", got, want)
+	}
+	if got := r.Port(); got != 8000 {
+		t.Errorf("Port() = %v, want 8000", got)
+	}
+	if lo, hi := r.Ports(); lo != 8000 || hi != 8010 {
+		t.Errorf("Ports() = %v, %v, want 8000, 8010", lo, hi)
+	}
+	if lo, hi := ConnectTCP(53).Ports(); lo != 53 || hi != 53 {
+		t.Errorf("ConnectTCP(53).Ports() = %v, %v, want 53, 53", lo, hi)
+	}
+}
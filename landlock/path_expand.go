@@ -0,0 +1,212 @@
+package landlock
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// PathExpansion describes which paths a path pattern of a filesystem
+// rule expanded to, for rules which use [FSRule.Expand].
+type PathExpansion struct {
+	// Pattern is the path as given in the rule.
+	Pattern string
+
+	// Paths are the paths which Pattern expanded to.  It is empty
+	// for patterns which were ignored because of
+	// [FSRule.IgnoreIfMissing].
+	Paths []string
+}
+
+// expansions expands the path patterns of the rule.  Patterns which
+// can not be expanded are reported as a *PathRuleError for access,
+// unless they are ignored because of IgnoreIfMissing.
+func (r FSRule) expansions(access AccessFSSet) ([]PathExpansion, error) {
+	var res []PathExpansion
+	var root fs.FS
+	if r.root != nil {
+		root = r.rootFS()
+	}
+	for _, pattern := range r.paths {
+		paths, err := expandPath(pattern, root)
+		if err != nil {
+			if !r.ignoreMissing || !errors.Is(err, syscall.ENOENT) {
+				return nil, newPathRuleError(pattern, access, err)
+			}
+		}
+		res = append(res, PathExpansion{Pattern: pattern, Paths: paths})
+	}
+	return res, nil
+}
+
+// expandedPaths returns the paths of the rule, after expanding them if
+// the rule uses Expand.
+func (r FSRule) expandedPaths(access AccessFSSet) ([]string, error) {
+	if !r.expand {
+		return r.paths, nil
+	}
+	exps, err := r.expansions(access)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, e := range exps {
+		paths = append(paths, e.Paths...)
+	}
+	return paths, nil
+}
+
+// expandRules returns the rules with the paths of filesystem rules
+// which use Expand replaced by what they expand to, so that the paths
+// can be compared lexically.  Paths which are missing are dropped if
+// the rule uses IgnoreIfMissing.
+func expandRules(rules []Rule) ([]Rule, error) {
+	res := make([]Rule, 0, len(rules))
+	for _, rule := range rules {
+		if r, ok := rule.(FSRule); ok && r.expand {
+			paths, err := r.expandedPaths(r.accessFS)
+			if err != nil {
+				return nil, err
+			}
+			r.paths, r.expand = paths, false
+			rule = r
+		}
+		res = append(res, rule)
+	}
+	return res, nil
+}
+
+// expandPath expands environment variables, a leading "~" and glob
+// patterns in pattern.  Glob patterns are matched in root, if it is
+// set.
+//
+// Errors about unset variables and patterns without matches wrap
+// ENOENT, so that they are ignored like missing paths.
+func expandPath(pattern string, root fs.FS) ([]string, error) {
+	p, err := expandEnv(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.ContainsAny(p, `*?[`) {
+		return []string{p}, nil
+	}
+
+	var matches []string
+	if root != nil {
+		matches, err = fs.Glob(root, p)
+	} else {
+		matches, err = filepath.Glob(p)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("expanding %q: %w", pattern, err)
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no files match %q: %w", p, syscall.ENOENT)
+	}
+	return matches, nil
+}
+
+// expandEnv expands a leading "~" to the home directory, and the
+// environment variables in s, in the forms $VAR, ${VAR} and
+// ${VAR:-default}.  Variables which are set to the empty string are
+// treated as unset.  A "$" which does not start a variable reference
+// is kept as is.
+func expandEnv(s string) (string, error) {
+	var b strings.Builder
+	if s == "~" || strings.HasPrefix(s, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("expanding ~: %v: %w", err, syscall.ENOENT)
+		}
+		b.WriteString(home)
+		s = s[1:]
+	}
+
+	for {
+		i := strings.IndexByte(s, '$')
+		if i < 0 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+		b.WriteString(s[:i])
+		s = s[i+1:]
+
+		var name, def string
+		hasDef := false
+		switch {
+		case strings.HasPrefix(s, "{"):
+			end := closingBrace(s)
+			if end < 0 {
+				return "", fmt.Errorf("missing closing brace in variable reference ${%v", s[1:])
+			}
+			name, def, hasDef = strings.Cut(s[1:end], ":-")
+			s = s[end+1:]
+			if !isEnvName(name) {
+				return "", fmt.Errorf("invalid variable name %q", name)
+			}
+		case len(s) > 0 && isEnvNameStart(s[0]):
+			n := 1
+			for n < len(s) && isEnvNameChar(s[n]) {
+				n++
+			}
+			name, s = s[:n], s[n:]
+		default:
+			b.WriteByte('$')
+			continue
+		}
+
+		val := os.Getenv(name)
+		if val == "" && hasDef {
+			var err error
+			if val, err = expandEnv(def); err != nil {
+				return "", err
+			}
+		}
+		if val == "" {
+			return "", fmt.Errorf("environment variable %v is not set: %w", name, syscall.ENOENT)
+		}
+		b.WriteString(val)
+	}
+}
+
+// closingBrace returns the index of the brace which closes the brace
+// at s[0], or -1.
+func closingBrace(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func isEnvName(s string) bool {
+	if s == "" || !isEnvNameStart(s[0]) {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		if !isEnvNameChar(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isEnvNameStart(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isEnvNameChar(c byte) bool {
+	return isEnvNameStart(c) || '0' <= c && c <= '9'
+}
@@ -0,0 +1,42 @@
+//go:build linux
+
+package landlock
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"syscall"
+	"testing"
+)
+
+func TestExpandGlobBeneath(t *testing.T) {
+	dir := t.TempDir()
+	other := t.TempDir()
+	for _, d := range []string{filepath.Join(dir, "a1"), filepath.Join(dir, "a2"), filepath.Join(other, "x1")} {
+		if err := os.Mkdir(d, 0o700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(other, filepath.Join(dir, "escape")); err != nil {
+		t.Fatal(err)
+	}
+	root, err := os.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer root.Close()
+
+	r := RODirs("a*").ResolveBeneath(root).Expand()
+	got, err := r.expandedPaths(0)
+	if want := []string{"a1", "a2"}; err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("expandedPaths() = %v, %v, want %v", got, err, want)
+	}
+
+	// Patterns are not matched through symlinks which escape root.
+	r = RODirs("escape/x*").ResolveBeneath(root).Expand()
+	if _, err := r.expandedPaths(0); !errors.Is(err, syscall.ENOENT) {
+		t.Errorf("expandedPaths() through escaping symlink = %v, want ENOENT", err)
+	}
+}
@@ -0,0 +1,146 @@
+package landlock
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("HOME", "/home/user")
+	t.Setenv("APP", "app")
+	t.Setenv("EMPTY", "")
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	for _, tt := range []struct {
+		in, want string
+	}{
+		{"/usr/lib", "/usr/lib"},
+		{"~", "/home/user"},
+		{"~/.cache", "/home/user/.cache"},
+		{"/srv/~x", "/srv/~x"},
+		{"$HOME/x", "/home/user/x"},
+		{"/opt/${APP}/data", "/opt/app/data"},
+		{"/opt/$APP.d", "/opt/app.d"},
+		{"${XDG_CONFIG_HOME:-~/.config}/app", "/home/user/.config/app"},
+		{"${APP:-other}", "app"},
+		{"${EMPTY:-${APP}}", "app"},
+		{"/cost/$5", "/cost/$5"},
+		{"/trailing$", "/trailing$"},
+	} {
+		got, err := expandEnv(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("expandEnv(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestExpandEnvErrors(t *testing.T) {
+	t.Setenv("EMPTY", "")
+	os.Unsetenv("LANDLOCK_TEST_UNSET")
+
+	for _, in := range []string{"$EMPTY/x", "${LANDLOCK_TEST_UNSET}", "${EMPTY:-$LANDLOCK_TEST_UNSET}"} {
+		if _, err := expandEnv(in); !errors.Is(err, syscall.ENOENT) {
+			t.Errorf("expandEnv(%q) = %v, want ENOENT", in, err)
+		}
+	}
+	for _, in := range []string{"${APP", "${A-B}", "${}"} {
+		_, err := expandEnv(in)
+		if err == nil || errors.Is(err, syscall.ENOENT) {
+			t.Errorf("expandEnv(%q) = %v, want syntax error", in, err)
+		}
+	}
+}
+
+func TestExpandGlob(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"python3.11", "python3.12", "perl5"} {
+		if err := os.Mkdir(filepath.Join(dir, name), 0o700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("LIBDIR", dir)
+
+	r := RODirs("$LIBDIR/python3.*", filepath.Join(dir, "perl5"), filepath.Join(dir, "ruby*")).IgnoreIfMissing().Expand()
+	exps, err := r.expansions(0)
+	if err != nil {
+		t.Fatalf("expansions(): %v", err)
+	}
+	want := []PathExpansion{
+		{Pattern: "$LIBDIR/python3.*", Paths: []string{filepath.Join(dir, "python3.11"), filepath.Join(dir, "python3.12")}},
+		{Pattern: filepath.Join(dir, "perl5"), Paths: []string{filepath.Join(dir, "perl5")}},
+		{Pattern: filepath.Join(dir, "ruby*")},
+	}
+	if !reflect.DeepEqual(exps, want) {
+		t.Errorf("expansions() = %v, want %v", exps, want)
+	}
+
+	// Without IgnoreIfMissing, patterns without matches are errors.
+	r = RODirs(filepath.Join(dir, "ruby*")).Expand()
+	var pathErr *PathRuleError
+	if _, err := r.expansions(0); !errors.As(err, &pathErr) || !errors.Is(err, syscall.ENOENT) {
+		t.Errorf("expansions() = %v, want PathRuleError wrapping ENOENT", err)
+	}
+
+	// Without Expand, paths are used literally.
+	r = RODirs("$LIBDIR/python3.*")
+	if got, err := r.expandedPaths(0); err != nil || !reflect.DeepEqual(got, []string{"$LIBDIR/python3.*"}) {
+		t.Errorf("expandedPaths() without Expand = %v, %v", got, err)
+	}
+}
+
+func TestExplainExpand(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"nvidia0", "nvidiactl"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rep, err := explain(V3, []Rule{RWFiles(filepath.Join(dir, "nvidia*")).Expand()}, abiInfos[3])
+	if err != nil {
+		t.Fatalf("explain(): %v", err)
+	}
+	exps := rep.Rules[0].Expansions
+	if len(exps) != 1 || len(exps[0].Paths) != 2 {
+		t.Fatalf("Expansions = %v, want one pattern with two paths", exps)
+	}
+	if got := len(rep.Rules[0].Targets); got != 2 {
+		t.Errorf("len(Targets) = %v, want 2", got)
+	}
+	if s := rep.String(); !strings.Contains(s, "Expanded ") || !strings.Contains(s, "nvidiactl") {
+		t.Errorf("String() = %q, want it to mention the expansion", s)
+	}
+}
+
+func TestExpandInPolicyComparisons(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a1", "a2"} {
+		if err := os.Mkdir(filepath.Join(dir, name), 0o700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("DIR", dir)
+	t.Setenv("UNSET_DIR", "") // Treated as unset.
+
+	p := Policy{V1, []Rule{RODirs("$DIR/a*").Expand()}}
+	if err := p.Check(accessFSRead, filepath.Join(dir, "a2", "f")); err != nil {
+		t.Errorf("Check() in expanded path: %v", err)
+	}
+	if !IsSubsetPolicy(p, Policy{V1, []Rule{RODirs(dir)}}) {
+		t.Errorf("IsSubsetPolicy() = false, want true")
+	}
+
+	// Paths which can not be expanded are reported, instead of being
+	// compared literally.
+	bad := []Rule{RODirs("$UNSET_DIR/a").Expand()}
+	if err := (Policy{V1, bad}).Check(accessFSRead, "/x"); !errors.Is(err, syscall.ENOENT) {
+		t.Errorf("Check() with unset variable = %v, want ENOENT", err)
+	}
+	if d := Diff(V1, nil, V1, bad); len(d.Unresolved) != 1 {
+		t.Errorf("Diff().Unresolved = %v, want one error", d.Unresolved)
+	}
+}
@@ -2,6 +2,10 @@ package landlock
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 
 	ll "github.com/landlock-lsm/go-landlock/landlock/syscall"
 )
@@ -10,8 +14,13 @@ import (
 type FSRule struct {
 	accessFS      AccessFSSet
 	paths         []string
-	enforceSubset bool // enforce that accessFS is a subset of cfg.handledAccessFS
-	ignoreMissing bool // ignore missing paths
+	files         []*os.File // open files to grant access to, in addition to paths
+	root          *os.File   // if set, paths are resolved beneath this directory
+	noSymlinks    bool       // reject symlinks when resolving paths
+	noFollow      bool       // reject symlinks as the final path component
+	expand        bool       // expand variables and glob patterns in paths
+	enforceSubset bool       // enforce that accessFS is a subset of cfg.handledAccessFS
+	ignoreMissing bool       // ignore missing paths
 }
 
 // withRights adds the given access rights to the rights enforced in the FSRule
@@ -77,8 +86,135 @@ func (r FSRule) IgnoreIfMissing() FSRule {
 	return r
 }
 
+// ResolveBeneath resolves the paths of the rule relative to the
+// directory root, instead of the current working directory.
+//
+// The paths are resolved with openat2(2) and RESOLVE_BENEATH, so that
+// resolution fails if a path is absolute, or if it escapes root
+// through ".." components or symlinks.  With [FSRule.Expand], glob
+// patterns are matched the same way.  The root directory needs to
+// stay open until the rule is enforced, or until the ruleset is
+// prepared with [Config.Prepare].
+func (r FSRule) ResolveBeneath(root *os.File) FSRule {
+	r.root = root
+	return r
+}
+
+// ResolveNoSymlinks makes the resolution of the rule's paths fail if
+// any path component is a symbolic link.
+//
+// The paths are resolved with openat2(2) and RESOLVE_NO_SYMLINKS.
+// This guards against path components being replaced with symlinks
+// between checking and enforcing a policy.
+func (r FSRule) ResolveNoSymlinks() FSRule {
+	r.noSymlinks = true
+	return r
+}
+
+// NoFollow makes the rule fail for paths which are symbolic links,
+// instead of granting access to the symlink's target.
+//
+// Unlike [FSRule.ResolveNoSymlinks], this only checks the final path
+// component.  To review which files the paths of a rule resolve to,
+// use [Config.Explain].
+func (r FSRule) NoFollow() FSRule {
+	r.noFollow = true
+	return r
+}
+
+// Expand makes the rule expand environment variables and glob
+// patterns in its paths when it is added to a ruleset:
+//
+//   - A leading "~" is replaced with the home directory.
+//   - $VAR and ${VAR} are replaced with the value of the environment
+//     variable VAR.  ${VAR:-default} uses default (which is expanded
+//     as well) if VAR is unset or empty.
+//   - Paths containing the glob characters "*", "?" or "[" are
+//     replaced with the matching files, as with [filepath.Glob].
+//
+// Expansion fails for unset variables and for glob patterns which
+// do not match any file, unless the rule uses
+// [FSRule.IgnoreIfMissing], in which case these paths are skipped.
+// [Config.Explain] reports what each path expanded to.
+//
+// Expansion happens when the ruleset is prepared, so that the matched
+// files are the ones which exist at that point in time.  Files which
+// are created later are not covered, even if they match a pattern.
+func (r FSRule) Expand() FSRule {
+	r.expand = true
+	return r
+}
+
 func (r FSRule) String() string {
-	return fmt.Sprintf("REQUIRE %v for paths %v", r.accessFS, r.paths)
+	var objs []string
+	if len(r.paths) > 0 {
+		var where string
+		if r.root != nil {
+			where = fmt.Sprintf(" beneath %v", r.root.Name())
+		}
+		objs = append(objs, fmt.Sprintf("paths %v%v", r.paths, where))
+	}
+	if len(r.files) > 0 {
+		objs = append(objs, fmt.Sprintf("files %v", fileNames(r.files)))
+	}
+	if len(objs) == 0 {
+		objs = append(objs, "paths []")
+	}
+	return fmt.Sprintf("REQUIRE %v for %v", r.accessFS, strings.Join(objs, " and "))
+}
+
+// Access returns the access rights which the rule asks for.  When the
+// rule is enforced, the rights which are not handled by the [Config]
+// are dropped, unless the rule was created with [PathAccess].
+func (r FSRule) Access() AccessFSSet {
+	return r.accessFS
+}
+
+// Paths returns the paths to which the rule grants access.  If the
+// rule uses [FSRule.ResolveBeneath], they are relative to [FSRule.Root].
+func (r FSRule) Paths() []string {
+	return slices.Clone(r.paths)
+}
+
+// Files returns the open files to which the rule grants access, as
+// given to [FDAccess].
+func (r FSRule) Files() []*os.File {
+	return slices.Clone(r.files)
+}
+
+// Root returns the directory which the rule's paths are resolved
+// beneath, as set with [FSRule.ResolveBeneath], or nil.
+func (r FSRule) Root() *os.File {
+	return r.root
+}
+
+// IgnoresMissing reports whether missing paths are ignored, as set
+// with [FSRule.IgnoreIfMissing].
+func (r FSRule) IgnoresMissing() bool {
+	return r.ignoreMissing
+}
+
+// names returns a description of each filesystem location in the
+// rule, for use in reports and lexical path comparisons.  For rules
+// which use Expand, these are the unexpanded patterns; see
+// expandRules.
+func (r FSRule) names() []string {
+	var names []string
+	for _, p := range r.paths {
+		if r.root != nil {
+			p = filepath.Join(r.root.Name(), p)
+		}
+		names = append(names, p)
+	}
+	return append(names, fileNames(r.files)...)
+}
+
+func fileNames(files []*os.File) []string {
+	var names []string
+	for _, f := range files {
+		names = append(names, f.Name())
+	}
+	return names
 }
 
 // compatibleWithConfig returns true if the given rule is compatible
@@ -112,6 +248,15 @@ func (r FSRule) downgrade(c Config) (out Rule, ok bool) {
 	return r.intersectRights(c.handledAccessFS), true
 }
 
+// effectiveAccess returns the access rights which the rule grants
+// when it is added to a ruleset for c.
+func (r FSRule) effectiveAccess(c Config) AccessFSSet {
+	if !r.enforceSubset {
+		return r.accessFS.intersect(c.handledAccessFS)
+	}
+	return r.accessFS
+}
+
 func hasRefer(a AccessFSSet) bool {
 	return a&ll.AccessFSRefer != 0
 }
@@ -143,6 +288,25 @@ func PathAccess(accessFS AccessFSSet, paths ...string) FSRule {
 	}
 }
 
+// FDAccess is a [Rule] which grants the access rights specified by
+// accessFS to the file hierarchies under the given open files.
+//
+// Unlike [PathAccess], FDAccess does not look up any paths, so the
+// rule applies to exactly the files which the caller holds open, even
+// if they are renamed or replaced in the meantime.  The files may be
+// opened with O_PATH.  They need to stay open until the rule is
+// enforced, or until the ruleset is prepared with [Config.Prepare].
+//
+// accessFS must be a subset of the permissions that the Config
+// restricts.
+func FDAccess(accessFS AccessFSSet, files ...*os.File) FSRule {
+	return FSRule{
+		accessFS:      accessFS,
+		files:         files,
+		enforceSubset: true,
+	}
+}
+
 // RODirs is a [Rule] which grants common read-only access to files
 // and directories and permits executing files.
 func RODirs(paths ...string) FSRule {
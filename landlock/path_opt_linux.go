@@ -5,6 +5,10 @@ package landlock
 import (
 	"errors"
 	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
 	"syscall"
 
 	ll "github.com/landlock-lsm/go-landlock/landlock/syscall"
@@ -12,38 +16,181 @@ import (
 )
 
 func (r FSRule) addToRuleset(rulesetFD int, c Config) error {
-	effectiveAccessFS := r.accessFS
-	if !r.enforceSubset {
-		effectiveAccessFS = effectiveAccessFS.intersect(c.handledAccessFS)
-	}
+	effectiveAccessFS := r.effectiveAccess(c)
 	if effectiveAccessFS == 0 {
 		// Adding this to the ruleset would be a no-op
 		// and result in an error.
 		return nil
 	}
-	for _, path := range r.paths {
-		if err := addPath(rulesetFD, path, effectiveAccessFS); err != nil {
+	paths, err := r.expandedPaths(effectiveAccessFS)
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if err := r.addPath(rulesetFD, path, effectiveAccessFS); err != nil {
 			if r.ignoreMissing && errors.Is(err, unix.ENOENT) {
 				continue // Skip this path.
 			}
-			return fmt.Errorf("populating ruleset for %q with access %v: %w", path, effectiveAccessFS, err)
+			return newPathRuleError(path, effectiveAccessFS, err)
+		}
+	}
+	for _, f := range r.files {
+		rc, err := f.SyscallConn()
+		if err == nil {
+			cerr := rc.Control(func(fd uintptr) {
+				err = addFD(rulesetFD, int(fd), effectiveAccessFS)
+			})
+			if cerr != nil {
+				err = cerr
+			}
+		}
+		if err != nil {
+			return newPathRuleError(f.Name(), effectiveAccessFS, err)
 		}
 	}
 	return nil
 }
 
-func addPath(rulesetFd int, path string, access AccessFSSet) error {
-	fd, err := syscall.Open(path, unix.O_PATH|unix.O_CLOEXEC, 0)
+func (r FSRule) addPath(rulesetFd int, path string, access AccessFSSet) error {
+	fd, err := r.open(path)
 	if err != nil {
-		return fmt.Errorf("open: %w", err)
+		return err
 	}
 	defer syscall.Close(fd)
 
+	return addFD(rulesetFd, fd, access)
+}
+
+// open opens path with O_PATH, honoring the resolution options of the
+// rule.
+func (r FSRule) open(path string) (int, error) {
+	fd, err := r.openat(path)
+	if err != nil || !r.noFollow {
+		return fd, err
+	}
+	var st unix.Stat_t
+	if err := unix.Fstat(fd, &st); err != nil {
+		syscall.Close(fd)
+		return -1, fmt.Errorf("fstat: %w", err)
+	}
+	if st.Mode&unix.S_IFMT == unix.S_IFLNK {
+		syscall.Close(fd)
+		return -1, fmt.Errorf("open: %q is a symbolic link: %w", path, unix.ELOOP)
+	}
+	return fd, nil
+}
+
+func (r FSRule) openat(path string) (int, error) {
+	flags := unix.O_PATH | unix.O_CLOEXEC
+	if r.noFollow {
+		flags |= unix.O_NOFOLLOW
+	}
+	if r.root == nil && !r.noSymlinks {
+		fd, err := syscall.Open(path, flags, 0)
+		if err != nil {
+			return -1, fmt.Errorf("open: %w", err)
+		}
+		return fd, nil
+	}
+
+	how := &unix.OpenHow{Flags: uint64(flags)}
+	if r.noSymlinks {
+		how.Resolve |= unix.RESOLVE_NO_SYMLINKS
+	}
+	if r.root == nil {
+		fd, err := unix.Openat2(unix.AT_FDCWD, path, how)
+		if err != nil {
+			return -1, fmt.Errorf("openat2: %w", err)
+		}
+		return fd, nil
+	}
+
+	how.Resolve |= unix.RESOLVE_BENEATH
+	return openBeneath(r.root, path, how)
+}
+
+// openBeneath opens path relative to the directory root with
+// openat2(2).
+func openBeneath(root *os.File, path string, how *unix.OpenHow) (int, error) {
+	rc, err := root.SyscallConn()
+	if err != nil {
+		return -1, fmt.Errorf("openat2: %w", err)
+	}
+	fd := -1
+	cerr := rc.Control(func(dirfd uintptr) {
+		fd, err = unix.Openat2(int(dirfd), path, how)
+	})
+	if cerr != nil {
+		err = cerr
+	}
+	if err != nil {
+		return -1, fmt.Errorf("openat2: %w", err)
+	}
+	return fd, nil
+}
+
+// rootFS returns a file system for matching glob patterns beneath the
+// rule's root.  Like the rule's paths, files are opened relative to
+// the root's file descriptor, so that the matches can not be outside
+// of it.
+func (r FSRule) rootFS() fs.FS {
+	return rootFS{r}
+}
+
+type rootFS struct {
+	r FSRule
+}
+
+func (fsys rootFS) openFile(op, name string, flags int) (*os.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	how := &unix.OpenHow{
+		Flags:   uint64(flags | unix.O_CLOEXEC),
+		Resolve: unix.RESOLVE_BENEATH,
+	}
+	if fsys.r.noSymlinks {
+		how.Resolve |= unix.RESOLVE_NO_SYMLINKS
+	}
+	fd, err := openBeneath(fsys.r.root, name, how)
+	if err != nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	return os.NewFile(uintptr(fd), name), nil
+}
+
+func (fsys rootFS) Open(name string) (fs.File, error) {
+	return fsys.openFile("open", name, unix.O_PATH)
+}
+
+func (fsys rootFS) Stat(name string) (fs.FileInfo, error) {
+	f, err := fsys.openFile("stat", name, unix.O_PATH)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return f.Stat()
+}
+
+func (fsys rootFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	f, err := fsys.openFile("readdir", name, unix.O_RDONLY|unix.O_DIRECTORY)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	entries, err := f.ReadDir(-1)
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return entries, err
+}
+
+func addFD(rulesetFd, fd int, access AccessFSSet) error {
 	pathBeneath := ll.PathBeneathAttr{
 		ParentFd:      fd,
 		AllowedAccess: uint64(access),
 	}
-	err = ll.LandlockAddPathBeneathRule(rulesetFd, &pathBeneath, 0)
+	err := ll.LandlockAddPathBeneathRule(rulesetFd, &pathBeneath, 0)
 	if err != nil {
 		if errors.Is(err, syscall.EINVAL) {
 			// The ruleset access permissions must be a superset of the ones we restrict to.
@@ -51,6 +198,8 @@ func addPath(rulesetFd int, path string, access AccessFSSet) error {
 			err = fmt.Errorf("inconsistent access rights (using directory access rights on a regular file?): %w", err)
 		} else if errors.Is(err, syscall.ENOMSG) && access == 0 {
 			err = fmt.Errorf("empty access rights: %w", err)
+		} else if errors.Is(err, syscall.EBADF) || errors.Is(err, syscall.EBADFD) {
+			err = fmt.Errorf("invalid file descriptor: %w", err)
 		} else {
 			// Other errors should never happen.
 			err = bug(err)
@@ -59,3 +208,41 @@ func addPath(rulesetFd int, path string, access AccessFSSet) error {
 	}
 	return nil
 }
+
+// resolveTarget returns the canonical path of the file which the rule
+// grants access to for path, or "" if it can not be determined.
+func (r FSRule) resolveTarget(path string) (string, error) {
+	fd, err := r.open(path)
+	if err != nil {
+		return "", err
+	}
+	defer syscall.Close(fd)
+	return fdPath(fd), nil
+}
+
+// fileTarget returns the canonical path of the open file f, or "" if
+// it can not be determined.
+func fileTarget(f *os.File) (string, error) {
+	rc, err := f.SyscallConn()
+	if err != nil {
+		return "", err
+	}
+	var target string
+	err = rc.Control(func(fd uintptr) {
+		target = fdPath(int(fd))
+	})
+	return target, err
+}
+
+// procSelfFD is the directory with the process's file descriptors.
+var procSelfFD = "/proc/self/fd"
+
+// fdPath returns the canonical path of the file opened as fd, or "" if
+// /proc is not available.
+func fdPath(fd int) string {
+	target, err := os.Readlink(fmt.Sprintf("%s/%d", procSelfFD, fd))
+	if err != nil {
+		return ""
+	}
+	return target
+}
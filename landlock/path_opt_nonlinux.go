@@ -2,8 +2,47 @@
 
 package landlock
 
-import "errors"
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
 
 func (r FSRule) addToRuleset(rulesetFD int, c Config) error {
 	return errors.New("Landlock is only supported on Linux")
 }
+
+// resolveTarget returns the canonical path of the file which the rule
+// grants access to for path, or "" if it can not be determined.
+func (r FSRule) resolveTarget(path string) (string, error) {
+	if r.root != nil {
+		path = filepath.Join(r.root.Name(), path)
+	}
+	if r.noFollow {
+		fi, err := os.Lstat(path)
+		if err != nil {
+			return "", err
+		}
+		if fi.Mode()&os.ModeSymlink != 0 {
+			return "", errors.New("is a symbolic link")
+		}
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(path)
+}
+
+// rootFS returns a file system for matching glob patterns beneath the
+// rule's root.
+func (r FSRule) rootFS() fs.FS {
+	return os.DirFS(r.root.Name())
+}
+
+// fileTarget returns the canonical path of the open file f, or "" if
+// it can not be determined.
+func fileTarget(f *os.File) (string, error) {
+	return f.Name(), nil
+}
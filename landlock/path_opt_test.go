@@ -0,0 +1,43 @@
+package landlock
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestFSRuleAccessors(t *testing.T) {
+	r := RODirs("/usr", "/etc").IgnoreIfMissing()
+	if got, want := r.Access(), accessFSRead; got != want {
+		t.Errorf("Access() = %v, want %v", got, want)
+	}
+	if got, want := r.Paths(), []string{"/usr", "/etc"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Paths() = %v, want %v", got, want)
+	}
+	r.Paths()[0] = "/modified"
+	if got := r.Paths()[0]; got != "/usr" {
+		t.Errorf("Paths() returned the rule's internal slice")
+	}
+	if !r.IgnoresMissing() {
+		t.Error("IgnoresMissing() = false, want true")
+	}
+	if r.Root() != nil || r.Files() != nil {
+		t.Errorf("Root() = %v, Files() = %v, want nil", r.Root(), r.Files())
+	}
+
+	root, err := os.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer root.Close()
+	r = FDAccess(accessFSRead, root).ResolveBeneath(root)
+	if got := r.Files(); len(got) != 1 || got[0] != root {
+		t.Errorf("Files() = %v, want [%v]", got, root.Name())
+	}
+	if r.Root() != root {
+		t.Errorf("Root() = %v, want %v", r.Root(), root.Name())
+	}
+	if r.IgnoresMissing() {
+		t.Error("IgnoresMissing() = true, want false")
+	}
+}
@@ -0,0 +1,550 @@
+package landlock
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// policyVersion is the newest policy format version understood by
+// LoadPolicy.
+const policyVersion = 1
+
+// policyFile is the on-disk representation of a policy.
+type policyFile struct {
+	Version          *int           `json:"version"`
+	ABI              *int           `json:"abi"`
+	HandledAccessFS  []string       `json:"handled_access_fs"`
+	HandledAccessNet []string       `json:"handled_access_net"`
+	Scoped           []string       `json:"scoped"`
+	BestEffort       bool           `json:"best_effort"`
+	Logging          policyLogging  `json:"logging"`
+	FS               []policyFSRule `json:"fs"`
+	Net              []policyNet    `json:"net"`
+}
+
+type policyLogging struct {
+	DisableForOriginatingProcess bool `json:"disable_for_originating_process"`
+	EnableForSubprocesses        bool `json:"enable_for_subprocesses"`
+	DisableForSubdomains         bool `json:"disable_for_subdomains"`
+}
+
+type policyFSRule struct {
+	Rule            string   `json:"rule"`
+	Paths           []string `json:"paths"`
+	Access          []string `json:"access"`
+	With            []string `json:"with"`
+	IgnoreIfMissing bool     `json:"ignore_if_missing"`
+	Expand          bool     `json:"expand"`
+}
+
+type policyNet struct {
+	Rule       string     `json:"rule"`
+	Ports      []uint16   `json:"ports"`
+	PortRanges [][]uint16 `json:"port_ranges"`
+}
+
+// PolicyError describes a problem in a policy read by [LoadPolicy].
+type PolicyError struct {
+	// Line and Column denote the 1-based position of the problem
+	// in the input.  They are 0 if the position is unknown.
+	Line, Column int
+
+	// Field is the path of the offending field within the policy,
+	// e.g. "fs[1].access[0]".  It is empty if unknown.
+	Field string
+
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *PolicyError) Error() string {
+	var b strings.Builder
+	if e.Line > 0 {
+		fmt.Fprintf(&b, "line %d, column %d: ", e.Line, e.Column)
+	}
+	if e.Field != "" {
+		fmt.Fprintf(&b, "%s: ", e.Field)
+	}
+	b.WriteString(e.Err.Error())
+	return b.String()
+}
+
+func (e *PolicyError) Unwrap() error {
+	return e.Err
+}
+
+// LoadPolicy reads a Landlock policy in JSON format from r and returns
+// the corresponding configuration and rules, which can be passed to
+// [Config.Restrict].  Policies in TOML format can be read with the
+// [github.com/landlock-lsm/go-landlock/landlock/policytoml] package.
+//
+// The policy format is versioned.  Version 1 looks like this:
+//
+//	{
+//	  "version": 1,
+//	  "abi": 9,
+//	  "best_effort": true,
+//	  "logging": {"enable_for_subprocesses": true},
+//	  "fs": [
+//	    {"rule": "ro_dirs", "paths": ["/usr", "/etc"]},
+//	    {"rule": "rw_dirs", "paths": ["/tmp"], "with": ["refer"]},
+//	    {"rule": "ro_dirs", "paths": ["${XDG_CONFIG_HOME:-~/.config}/app"], "expand": true},
+//	    {"rule": "path_access", "access": ["read_file"], "paths": ["/opt/x"], "ignore_if_missing": true}
+//	  ],
+//	  "net": [
+//	    {"rule": "connect_tcp", "ports": [53, 443]},
+//	    {"rule": "bind_tcp", "ports": [8080], "port_ranges": [[9000, 9099]]}
+//	  ]
+//	}
+//
+// The top-level fields are:
+//
+//   - "version" (required): The policy format version, currently 1.
+//   - "abi": Restrict all access rights known at the given Landlock
+//     ABI version, as in [V1] to [V9].
+//   - "handled_access_fs", "handled_access_net", "scoped": Lists of
+//     access rights and scopes to restrict, using the names accepted
+//     by [ParseAccessFSSet], [ParseAccessNetSet] and
+//     [ParseScopedSet].  These are mutually exclusive with "abi".
+//   - "best_effort": Use [Config.BestEffort].
+//   - "logging": Set the logging flags "disable_for_originating_process",
+//     "enable_for_subprocesses" and "disable_for_subdomains", as
+//     documented at [Config.DisableLoggingForOriginatingProcess],
+//     [Config.EnableLoggingForSubprocesses] and
+//     [Config.DisableLoggingForSubdomains].
+//   - "fs": Filesystem rules.  "rule" is one of "ro_dirs", "rw_dirs",
+//     "ro_files", "rw_files" (see [RODirs], [RWDirs], [ROFiles] and
+//     [RWFiles]) or "path_access" (see [PathAccess]), which
+//     additionally requires the "access" list (using the names
+//     accepted by [ParseAccessFSSet]).  "with" may list the extra
+//     access rights "refer", "ioctl_dev" and "resolve_unix",
+//     "ignore_if_missing" corresponds to [FSRule.IgnoreIfMissing], and
+//     "expand" corresponds to [FSRule.Expand].
+//   - "net": Network rules.  "rule" is one of "bind_tcp" and
+//     "connect_tcp" (see [BindTCP] and [ConnectTCP]).  "ports" lists
+//     single ports, and "port_ranges" lists inclusive ranges of ports
+//     as [first, last] pairs (see [BindTCPRange] and
+//     [ConnectTCPRange]).  At least one of them is required.
+//
+// Unknown fields are rejected.  Errors about the policy content are
+// reported as [*PolicyError], which carries the position in the input
+// and the path of the offending field.
+func LoadPolicy(r io.Reader) (Config, []Rule, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Config{}, nil, err
+	}
+
+	var pf policyFile
+	idx, err := decodeJSONPolicy(data, &pf)
+	if err != nil {
+		return Config{}, nil, err
+	}
+
+	cfg, rules, err := pf.build()
+	if err != nil {
+		var pe *PolicyError
+		if errors.As(err, &pe) {
+			pos := idx[pe.Field]
+			pe.Line, pe.Column = pos.line, pos.col
+		}
+		return Config{}, nil, err
+	}
+	return cfg, rules, nil
+}
+
+func (pf *policyFile) build() (Config, []Rule, error) {
+	if pf.Version == nil {
+		return Config{}, nil, &PolicyError{Err: errors.New("missing policy version")}
+	}
+	if *pf.Version != policyVersion {
+		return Config{}, nil, &PolicyError{
+			Field: "version",
+			Err:   fmt.Errorf("unsupported policy version %d, want %d", *pf.Version, policyVersion),
+		}
+	}
+
+	var c Config
+	explicit := pf.HandledAccessFS != nil || pf.HandledAccessNet != nil || pf.Scoped != nil
+	switch {
+	case pf.ABI != nil && explicit:
+		return Config{}, nil, &PolicyError{
+			Field: "abi",
+			Err:   errors.New("abi can not be combined with handled_access_fs, handled_access_net or scoped"),
+		}
+	case pf.ABI != nil:
+		if *pf.ABI < 1 || *pf.ABI >= len(abiInfos) {
+			return Config{}, nil, &PolicyError{
+				Field: "abi",
+				Err:   fmt.Errorf("unsupported ABI version %d; upgrade go-landlock?", *pf.ABI),
+			}
+		}
+		c = abiInfos[*pf.ABI].asConfig()
+	case explicit:
+		fs, err := parsePolicyNames("handled_access_fs", pf.HandledAccessFS, accessFSSyntax)
+		if err != nil {
+			return Config{}, nil, err
+		}
+		net, err := parsePolicyNames("handled_access_net", pf.HandledAccessNet, accessNetSyntax)
+		if err != nil {
+			return Config{}, nil, err
+		}
+		scoped, err := parsePolicyNames("scoped", pf.Scoped, scopedSyntax)
+		if err != nil {
+			return Config{}, nil, err
+		}
+		c = Config{
+			handledAccessFS:  AccessFSSet(fs),
+			handledAccessNet: AccessNetSet(net),
+			scoped:           ScopedSet(scoped),
+		}
+	default:
+		return Config{}, nil, &PolicyError{
+			Err: errors.New("missing abi or handled_access_fs, handled_access_net, scoped"),
+		}
+	}
+
+	if pf.BestEffort {
+		c = c.BestEffort()
+	}
+	if pf.Logging.DisableForOriginatingProcess {
+		c = c.DisableLoggingForOriginatingProcess()
+	}
+	if pf.Logging.EnableForSubprocesses {
+		c = c.EnableLoggingForSubprocesses()
+	}
+	if pf.Logging.DisableForSubdomains {
+		c = c.DisableLoggingForSubdomains()
+	}
+
+	var rules []Rule
+	for i, pr := range pf.FS {
+		field := fmt.Sprintf("fs[%d]", i)
+		rule, err := pr.build(field)
+		if err != nil {
+			return Config{}, nil, err
+		}
+		if !rule.compatibleWithConfig(c) {
+			return Config{}, nil, &PolicyError{
+				Field: field,
+				Err:   fmt.Errorf("rule %v is incompatible with %v", rule, c),
+			}
+		}
+		rules = append(rules, rule)
+	}
+	for i, pn := range pf.Net {
+		field := fmt.Sprintf("net[%d]", i)
+		var makeRule func(lo, hi uint16) NetRule
+		switch pn.Rule {
+		case "bind_tcp":
+			makeRule = BindTCPRange
+		case "connect_tcp":
+			makeRule = ConnectTCPRange
+		case "":
+			return Config{}, nil, &PolicyError{Field: field, Err: errors.New("missing rule type")}
+		default:
+			return Config{}, nil, &PolicyError{
+				Field: field + ".rule",
+				Err:   fmt.Errorf("unknown network rule type %q", pn.Rule),
+			}
+		}
+		if len(pn.Ports) == 0 && len(pn.PortRanges) == 0 {
+			return Config{}, nil, &PolicyError{Field: field, Err: errors.New("missing ports or port_ranges")}
+		}
+		var netRules []NetRule
+		for _, port := range pn.Ports {
+			netRules = append(netRules, makeRule(port, port))
+		}
+		for j, pr := range pn.PortRanges {
+			if len(pr) != 2 || pr[0] > pr[1] {
+				return Config{}, nil, &PolicyError{
+					Field: fmt.Sprintf("%s.port_ranges[%d]", field, j),
+					Err:   fmt.Errorf("invalid port range %v, want [first, last]", pr),
+				}
+			}
+			netRules = append(netRules, makeRule(pr[0], pr[1]))
+		}
+		for _, rule := range netRules {
+			if !rule.compatibleWithConfig(c) {
+				return Config{}, nil, &PolicyError{
+					Field: field,
+					Err:   fmt.Errorf("rule %v is incompatible with %v", rule, c),
+				}
+			}
+			rules = append(rules, rule)
+		}
+	}
+	return c, rules, nil
+}
+
+func (pr *policyFSRule) build(field string) (FSRule, error) {
+	if len(pr.Paths) == 0 {
+		return FSRule{}, &PolicyError{Field: field, Err: errors.New("missing paths")}
+	}
+
+	var rule FSRule
+	switch pr.Rule {
+	case "ro_dirs":
+		rule = RODirs(pr.Paths...)
+	case "rw_dirs":
+		rule = RWDirs(pr.Paths...)
+	case "ro_files":
+		rule = ROFiles(pr.Paths...)
+	case "rw_files":
+		rule = RWFiles(pr.Paths...)
+	case "path_access":
+		if pr.Access == nil {
+			return FSRule{}, &PolicyError{Field: field, Err: errors.New("path_access rule without access")}
+		}
+		access, err := parsePolicyNames(field+".access", pr.Access, accessFSSyntax)
+		if err != nil {
+			return FSRule{}, err
+		}
+		rule = PathAccess(AccessFSSet(access), pr.Paths...)
+	case "":
+		return FSRule{}, &PolicyError{Field: field, Err: errors.New("missing rule type")}
+	default:
+		return FSRule{}, &PolicyError{
+			Field: field + ".rule",
+			Err:   fmt.Errorf("unknown filesystem rule type %q", pr.Rule),
+		}
+	}
+	if pr.Access != nil && pr.Rule != "path_access" {
+		return FSRule{}, &PolicyError{
+			Field: field + ".access",
+			Err:   fmt.Errorf("access can only be used with path_access rules, not %q", pr.Rule),
+		}
+	}
+
+	for i, w := range pr.With {
+		switch w {
+		case "refer":
+			rule = rule.WithRefer()
+		case "ioctl_dev":
+			rule = rule.WithIoctlDev()
+		case "resolve_unix":
+			rule = rule.WithResolveUnix()
+		default:
+			return FSRule{}, &PolicyError{
+				Field: fmt.Sprintf("%s.with[%d]", field, i),
+				Err:   fmt.Errorf("unknown extra access right %q", w),
+			}
+		}
+	}
+	if pr.IgnoreIfMissing {
+		rule = rule.IgnoreIfMissing()
+	}
+	if pr.Expand {
+		rule = rule.Expand()
+	}
+	return rule, nil
+}
+
+// parsePolicyNames converts a list of access right names into a bit set.
+func parsePolicyNames(field string, names []string, syn accessSetSyntax) (uint64, error) {
+	var a uint64
+	for i, name := range names {
+		v, err := syn.parseName(name)
+		if err != nil {
+			return 0, &PolicyError{Field: fmt.Sprintf("%s[%d]", field, i), Err: err}
+		}
+		a |= v
+	}
+	return a, nil
+}
+
+// policyPos is a 1-based line and column in the policy input.
+type policyPos struct {
+	line, col int
+}
+
+// policyIndex maps field paths (e.g. "fs[1].access[0]") to their
+// positions in the input.  The empty path denotes the whole policy.
+type policyIndex map[string]policyPos
+
+// policyFieldType returns the type of the field which is named key in
+// the policy file format, for the struct type t.
+func policyFieldType(t reflect.Type, key string) (reflect.Type, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if name, _, _ := strings.Cut(f.Tag.Get("json"), ","); name == key {
+			return f.Type, true
+		}
+	}
+	return nil, false
+}
+
+// decodePolicyValue decodes the policy from its JSON encoding in data,
+// and reports type errors at the positions in idx.
+func decodePolicyValue(data []byte, idx policyIndex, pf *policyFile) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(pf)
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		field := jsonFieldPath(typeErr.Field)
+		pos := idx[field]
+		return &PolicyError{Line: pos.line, Column: pos.col, Field: field, Err: err}
+	}
+	return err
+}
+
+// decodeJSONPolicy decodes the JSON policy in data into pf.
+func decodeJSONPolicy(data []byte, pf *policyFile) (policyIndex, error) {
+	w := &jsonIndexer{data: data, idx: policyIndex{}}
+	if err := w.index(); err != nil {
+		return nil, w.policyError(err)
+	}
+	if err := decodePolicyValue(data, w.idx, pf); err != nil {
+		return nil, w.policyError(err)
+	}
+	return w.idx, nil
+}
+
+// jsonIndexer walks a JSON document, records the position of each
+// field, and rejects fields which are not part of the policy format.
+type jsonIndexer struct {
+	data []byte
+	dec  *json.Decoder
+	idx  policyIndex
+}
+
+func (w *jsonIndexer) index() error {
+	w.dec = json.NewDecoder(bytes.NewReader(w.data))
+	if err := w.walk("", reflect.TypeFor[policyFile]()); err != nil {
+		return err
+	}
+	if _, err := w.dec.Token(); err != io.EOF {
+		return &json.SyntaxError{Offset: w.dec.InputOffset()}
+	}
+	return nil
+}
+
+// walk records the positions of the JSON value which comes next in
+// the input, and of its elements.  t is the type of the value in the
+// policy format, or nil if it is not known.
+func (w *jsonIndexer) walk(field string, t reflect.Type) error {
+	if _, ok := w.idx[field]; !ok {
+		w.idx[field] = w.position(w.dec.InputOffset())
+	}
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	tok, err := w.dec.Token()
+	if err != nil {
+		return err
+	}
+	switch tok {
+	case json.Delim('{'):
+		for w.dec.More() {
+			pos := w.position(w.dec.InputOffset())
+			key, err := w.dec.Token()
+			if err != nil {
+				return err
+			}
+			child := key.(string)
+			if field != "" {
+				child = field + "." + child
+			}
+			w.idx[child] = pos
+			var childType reflect.Type
+			if t != nil && t.Kind() == reflect.Struct {
+				var ok bool
+				if childType, ok = policyFieldType(t, key.(string)); !ok {
+					return &PolicyError{Line: pos.line, Column: pos.col, Field: child, Err: errors.New("unknown field")}
+				}
+			}
+			if err := w.walk(child, childType); err != nil {
+				return err
+			}
+		}
+		_, err = w.dec.Token() // '}'
+		return err
+	case json.Delim('['):
+		var elemType reflect.Type
+		if t != nil && t.Kind() == reflect.Slice {
+			elemType = t.Elem()
+		}
+		for i := 0; w.dec.More(); i++ {
+			if err := w.walk(fmt.Sprintf("%s[%d]", field, i), elemType); err != nil {
+				return err
+			}
+		}
+		_, err = w.dec.Token() // ']'
+		return err
+	}
+	return nil
+}
+
+// position returns the position of the token which follows the given
+// input offset.  The offsets reported by the decoder point to the end
+// of the previous token.
+func (w *jsonIndexer) position(off int64) policyPos {
+	for off < int64(len(w.data)) && strings.IndexByte(" \t\r\n,:", w.data[off]) >= 0 {
+		off++
+	}
+	line, col := offsetPosition(w.data, off)
+	return policyPos{line, col}
+}
+
+// policyError converts errors from encoding/json into a *PolicyError.
+func (w *jsonIndexer) policyError(err error) error {
+	var (
+		pe        *PolicyError
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+	)
+	switch {
+	case errors.As(err, &pe):
+		if pe.Line == 0 && errors.As(pe.Err, &typeErr) {
+			// The field path reported by encoding/json differs
+			// between Go versions; fall back to the offset.
+			pe.Line, pe.Column = offsetPosition(w.data, typeErr.Offset)
+		}
+		return err
+	case errors.As(err, &syntaxErr):
+		// The offset points just behind the offending character.
+		line, col := offsetPosition(w.data, max(syntaxErr.Offset-1, 0))
+		return &PolicyError{Line: line, Column: col, Err: err}
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		line, col := offsetPosition(w.data, int64(len(w.data)))
+		return &PolicyError{Line: line, Column: col, Err: io.ErrUnexpectedEOF}
+	}
+	return &PolicyError{Err: err}
+}
+
+// offsetPosition converts a byte offset in data into a 1-based line
+// and column.
+func offsetPosition(data []byte, off int64) (line, col int) {
+	if off > int64(len(data)) {
+		off = int64(len(data))
+	}
+	before := data[:off]
+	line = bytes.Count(before, []byte("\n")) + 1
+	col = int(off) - (bytes.LastIndexByte(before, '\n') + 1) + 1
+	return line, col
+}
+
+// jsonFieldPath converts field paths as reported by encoding/json
+// (e.g. "fs.0.access.1") into the notation used by PolicyError (e.g.
+// "fs[0].access[1]").
+func jsonFieldPath(field string) string {
+	var b strings.Builder
+	for i, part := range strings.Split(field, ".") {
+		if _, err := strconv.Atoi(part); err == nil && i > 0 {
+			fmt.Fprintf(&b, "[%s]", part)
+			continue
+		}
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(part)
+	}
+	return b.String()
+}
@@ -0,0 +1,278 @@
+package landlock
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	ll "github.com/landlock-lsm/go-landlock/landlock/syscall"
+)
+
+func TestLoadPolicy(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		policy    string
+		wantCfg   Config
+		wantRules []Rule
+	}{
+		{
+			name:    "ABIPreset",
+			policy:  `{"version": 1, "abi": 5}`,
+			wantCfg: V5,
+		},
+		{
+			name: "Explicit",
+			policy: `{
+				"version": 1,
+				"handled_access_fs": ["read_file", "write_file"],
+				"handled_access_net": ["connect_tcp"],
+				"scoped": ["signal"]
+			}`,
+			wantCfg: Config{
+				handledAccessFS:  ll.AccessFSReadFile | ll.AccessFSWriteFile,
+				handledAccessNet: ll.AccessNetConnectTCP,
+				scoped:           ll.ScopeSignal,
+			},
+		},
+		{
+			name: "Everything",
+			policy: `{
+				"version": 1,
+				"abi": 9,
+				"best_effort": true,
+				"logging": {
+					"disable_for_originating_process": true,
+					"enable_for_subprocesses": true,
+					"disable_for_subdomains": true
+				},
+				"fs": [
+					{"rule": "ro_dirs", "paths": ["/usr", "/etc"]},
+					{"rule": "rw_dirs", "paths": ["/tmp"], "with": ["refer", "ioctl_dev", "resolve_unix"]},
+					{"rule": "ro_files", "paths": ["/a"]},
+					{"rule": "ro_dirs", "paths": ["~/.config/*"], "expand": true},
+					{"rule": "rw_files", "paths": ["/b"], "ignore_if_missing": true},
+					{"rule": "path_access", "access": ["read_file", "read_dir"], "paths": ["/c"]}
+				],
+				"net": [
+					{"rule": "connect_tcp", "ports": [53, 443]},
+					{"rule": "bind_tcp", "ports": [8080]}
+				]
+			}`,
+			wantCfg: V9.BestEffort().
+				DisableLoggingForOriginatingProcess().
+				EnableLoggingForSubprocesses().
+				DisableLoggingForSubdomains(),
+			wantRules: []Rule{
+				RODirs("/usr", "/etc"),
+				RWDirs("/tmp").WithRefer().WithIoctlDev().WithResolveUnix(),
+				ROFiles("/a"),
+				RODirs("~/.config/*").Expand(),
+				RWFiles("/b").IgnoreIfMissing(),
+				PathAccess(ll.AccessFSReadFile|ll.AccessFSReadDir, "/c"),
+				ConnectTCP(53),
+				ConnectTCP(443),
+				BindTCP(8080),
+			},
+		},
+		{
+			name: "PortRanges",
+			policy: `{
+				"version": 1,
+				"abi": 4,
+				"net": [
+					{"rule": "connect_tcp", "ports": [443], "port_ranges": [[8000, 8999], [53, 53]]}
+				]
+			}`,
+			wantCfg: V4,
+			wantRules: []Rule{
+				ConnectTCP(443),
+				ConnectTCPRange(8000, 8999),
+				ConnectTCP(53),
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, rules, err := LoadPolicy(strings.NewReader(tt.policy))
+			if err != nil {
+				t.Fatalf("LoadPolicy(): %v", err)
+			}
+			if cfg != tt.wantCfg {
+				t.Errorf("cfg = %v, want %v", cfg, tt.wantCfg)
+			}
+			if got, want := fmt.Sprint(rules), fmt.Sprint(tt.wantRules); got != want {
+				t.Errorf("rules = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestLoadPolicyErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		policy     string
+		wantLine   int
+		wantColumn int
+		wantField  string
+		anyField   bool
+		wantErr    string
+	}{
+		{
+			name:     "MissingVersion",
+			policy:   `{"abi": 1}`,
+			wantErr:  "missing policy version",
+			wantLine: 1,
+		},
+		{
+			name:      "WrongVersion",
+			policy:    "{\n  \"version\": 2\n}",
+			wantLine:  2,
+			wantField: "version",
+			wantErr:   "unsupported policy version 2",
+		},
+		{
+			name:       "SyntaxError",
+			policy:     "{\n  \"version\": 1,\n  \"abi\": 9,,\n}",
+			wantLine:   3,
+			wantColumn: 12,
+			wantErr:    "invalid character",
+		},
+		{
+			name:     "Truncated",
+			policy:   "{\n  \"version\": 1,",
+			wantLine: 2,
+			wantErr:  "unexpected end of JSON input",
+		},
+		{
+			name:       "UnknownField",
+			policy:     "{\n  \"version\": 1,\n  \"abi\": 9,\n  \"fs\": [{\"rule\": \"ro_dirs\", \"pathz\": [\"/\"]}]\n}",
+			wantLine:   4,
+			wantColumn: 30,
+			wantField:  "fs[0].pathz",
+			wantErr:    "unknown field",
+		},
+		{
+			name:       "UnknownFieldNameUsedElsewhere",
+			policy:     "{\n  \"version\": 1,\n  \"abi\": 9,\n  \"fs\": [{\"rule\": \"ro_dirs\", \"paths\": [\"/\"]}],\n  \"net\": [{\"rule\": \"bind_tcp\", \"paths\": [\"/\"]}]\n}",
+			wantLine:   5,
+			wantColumn: 32,
+			wantField:  "net[0].paths",
+			wantErr:    "unknown field",
+		},
+		{
+			name:     "PortOutOfRange",
+			policy:   "{\n  \"version\": 1,\n  \"abi\": 9,\n  \"net\": [{\"rule\": \"bind_tcp\", \"ports\": [70000]}]\n}",
+			wantLine: 4,
+			// The field path reported by encoding/json differs
+			// between Go versions.
+			anyField: true,
+			wantErr:  "cannot unmarshal",
+		},
+		{
+			name:       "UnknownAccessRight",
+			policy:     "{\n  \"version\": 1,\n  \"abi\": 9,\n  \"fs\": [\n    {\"rule\": \"path_access\", \"paths\": [\"/\"], \"access\": [\"read_file\", \"red_file\"]}\n  ]\n}",
+			wantLine:   5,
+			wantColumn: 69,
+			wantField:  "fs[0].access[1]",
+			wantErr:    `unknown access right "red_file"`,
+		},
+		{
+			name:      "UnknownRuleType",
+			policy:    "{\n  \"version\": 1,\n  \"abi\": 9,\n  \"fs\": [\n    {\"rule\": \"ro_dir\", \"paths\": [\"/\"]}\n  ]\n}",
+			wantLine:  5,
+			wantField: "fs[0].rule",
+			wantErr:   `unknown filesystem rule type "ro_dir"`,
+		},
+		{
+			name:      "UnknownWith",
+			policy:    `{"version": 1, "abi": 9, "fs": [{"rule": "rw_dirs", "paths": ["/"], "with": ["truncate"]}]}`,
+			wantLine:  1,
+			wantField: "fs[0].with[0]",
+			wantErr:   `unknown extra access right "truncate"`,
+		},
+		{
+			name:      "MissingPaths",
+			policy:    `{"version": 1, "abi": 9, "fs": [{"rule": "rw_dirs"}]}`,
+			wantLine:  1,
+			wantField: "fs[0]",
+			wantErr:   "missing paths",
+		},
+		{
+			name:      "AccessOutsideHandled",
+			policy:    `{"version": 1, "handled_access_fs": ["read_file"], "fs": [{"rule": "path_access", "access": ["write_file"], "paths": ["/"]}]}`,
+			wantLine:  1,
+			wantField: "fs[0]",
+			wantErr:   "incompatible",
+		},
+		{
+			name:      "ReferOnV1",
+			policy:    `{"version": 1, "abi": 1, "fs": [{"rule": "rw_dirs", "paths": ["/"], "with": ["refer"]}]}`,
+			wantLine:  1,
+			wantField: "fs[0]",
+			wantErr:   "incompatible",
+		},
+		{
+			name:      "NetOutsideHandled",
+			policy:    `{"version": 1, "abi": 3, "net": [{"rule": "bind_tcp", "ports": [80]}]}`,
+			wantLine:  1,
+			wantField: "net[0]",
+			wantErr:   "incompatible",
+		},
+		{
+			name:      "ABIAndExplicit",
+			policy:    `{"version": 1, "abi": 3, "scoped": ["signal"]}`,
+			wantLine:  1,
+			wantField: "abi",
+			wantErr:   "can not be combined",
+		},
+		{
+			name:      "UnknownABI",
+			policy:    `{"version": 1, "abi": 1000}`,
+			wantLine:  1,
+			wantField: "abi",
+			wantErr:   "unsupported ABI version",
+		},
+		{
+			name:      "MissingPorts",
+			policy:    `{"version": 1, "abi": 4, "net": [{"rule": "bind_tcp"}]}`,
+			wantLine:  1,
+			wantField: "net[0]",
+			wantErr:   "missing ports or port_ranges",
+		},
+		{
+			name:       "ReversedPortRange",
+			policy:     "{\n  \"version\": 1,\n  \"abi\": 4,\n  \"net\": [{\"rule\": \"bind_tcp\", \"port_ranges\": [[80, 90], [90, 80]]}]\n}",
+			wantLine:   4,
+			wantColumn: 58,
+			wantField:  "net[0].port_ranges[1]",
+			wantErr:    "invalid port range [90 80]",
+		},
+		{
+			name:      "ShortPortRange",
+			policy:    `{"version": 1, "abi": 4, "net": [{"rule": "bind_tcp", "port_ranges": [[80]]}]}`,
+			wantLine:  1,
+			wantField: "net[0].port_ranges[0]",
+			wantErr:   "invalid port range",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := LoadPolicy(strings.NewReader(tt.policy))
+			var pe *PolicyError
+			if !errors.As(err, &pe) {
+				t.Fatalf("LoadPolicy() = %v, want *PolicyError", err)
+			}
+			if pe.Line != tt.wantLine {
+				t.Errorf("Line = %v, want %v (err: %v)", pe.Line, tt.wantLine, err)
+			}
+			if tt.wantColumn != 0 && pe.Column != tt.wantColumn {
+				t.Errorf("Column = %v, want %v (err: %v)", pe.Column, tt.wantColumn, err)
+			}
+			if !tt.anyField && pe.Field != tt.wantField {
+				t.Errorf("Field = %q, want %q (err: %v)", pe.Field, tt.wantField, err)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %q, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
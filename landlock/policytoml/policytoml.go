@@ -0,0 +1,125 @@
+// Package policytoml reads Landlock policies in TOML format.
+//
+// The policy format is the one documented at [landlock.LoadPolicy],
+// with TOML tables and arrays of tables in place of JSON objects.  A
+// policy looks like this:
+//
+//	version = 1
+//	abi = 9
+//	best_effort = true
+//	logging = { enable_for_subprocesses = true }
+//
+//	[[fs]]
+//	rule = "ro_dirs"
+//	paths = ["/usr", "/etc"]
+//
+//	[[fs]]
+//	rule = "rw_dirs"
+//	paths = ["/tmp"]
+//	with = ["refer"]
+//
+//	[[net]]
+//	rule = "connect_tcp"
+//	ports = [53, 443]
+//	port_ranges = [[8000, 8999]]
+//
+// The TOML document is parsed with [github.com/BurntSushi/toml].
+package policytoml
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+
+	"github.com/BurntSushi/toml"
+	"github.com/landlock-lsm/go-landlock/landlock"
+)
+
+// Load reads a Landlock policy in TOML format from r and returns the
+// corresponding configuration and rules, which can be passed to
+// [landlock.Config.Restrict].
+//
+// Errors about the policy are reported as [*landlock.PolicyError].
+// TOML syntax errors carry their position in the input.  Errors about
+// the policy content carry the path of the offending field, but no
+// position.
+func Load(r io.Reader) (landlock.Config, []landlock.Rule, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return landlock.Config{}, nil, err
+	}
+
+	var doc map[string]any
+	if _, err := toml.Decode(string(data), &doc); err != nil {
+		var pe toml.ParseError
+		if errors.As(err, &pe) {
+			return landlock.Config{}, nil, &landlock.PolicyError{
+				Line:   pe.Position.Line,
+				Column: pe.Position.Col,
+				Err:    errors.New(pe.Message),
+			}
+		}
+		return landlock.Config{}, nil, err
+	}
+	if err := checkValue(doc, ""); err != nil {
+		return landlock.Config{}, nil, err
+	}
+
+	// Hand the policy to the JSON decoder, so that both formats are
+	// subject to the same checks.
+	js, err := json.Marshal(doc)
+	if err != nil {
+		return landlock.Config{}, nil, err
+	}
+	cfg, rules, err := landlock.LoadPolicy(bytes.NewReader(js))
+	if err != nil {
+		var pe *landlock.PolicyError
+		if errors.As(err, &pe) {
+			// The position refers to the JSON encoding.
+			pe.Line, pe.Column = 0, 0
+		}
+		return landlock.Config{}, nil, err
+	}
+	return cfg, rules, nil
+}
+
+// checkValue rejects the TOML value types which the policy format
+// does not use.  Floats would otherwise pass as integers, and dates
+// and times as strings.
+func checkValue(v any, field string) error {
+	switch v := v.(type) {
+	case map[string]any:
+		for _, k := range slices.Sorted(maps.Keys(v)) {
+			child := k
+			if field != "" {
+				child = field + "." + k
+			}
+			if err := checkValue(v[k], child); err != nil {
+				return err
+			}
+		}
+	case []map[string]any:
+		for i, e := range v {
+			if err := checkValue(e, fmt.Sprintf("%s[%d]", field, i)); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for i, e := range v {
+			if err := checkValue(e, fmt.Sprintf("%s[%d]", field, i)); err != nil {
+				return err
+			}
+		}
+	case string, int64, bool:
+	default:
+		return &landlock.PolicyError{
+			Field: field,
+			Err:   errors.New("floats, dates and times are not supported"),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package policytoml
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/landlock-lsm/go-landlock/landlock"
+	ll "github.com/landlock-lsm/go-landlock/landlock/syscall"
+)
+
+func TestLoad(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		policy    string
+		wantCfg   landlock.Config
+		wantRules []landlock.Rule
+	}{
+		{
+			name: "Everything",
+			policy: `# Comments are permitted.
+version = 1
+abi = 9
+best_effort = true
+logging.disable_for_originating_process = true
+logging.enable_for_subprocesses = true
+logging.disable_for_subdomains = true
+
+[[fs]]
+rule = "ro_dirs"
+paths = ["/usr", '/etc']
+
+[[fs]]
+rule = "rw_dirs"
+paths = [
+  "/tmp",  # Trailing commas are permitted as well.
+]
+with = ["refer", "ioctl_dev", "resolve_unix"]
+
+[[fs]]
+"rule" = "ro_files"
+paths = ["/a"]
+
+[[fs]]
+rule = "ro_dirs"
+paths = ["~/.config/*"]
+expand = true
+
+[[fs]]
+rule = "rw_files"
+paths = ["/b"]
+ignore_if_missing = true
+
+[[fs]]
+rule = "path_access"
+access = ["read_file", "read_dir"]
+paths = ["/c"]
+
+[[net]]
+rule = "connect_tcp"
+ports = [53, 443]
+
+[[net]]
+rule = "bind_tcp"
+ports = [8_080]
+port_ranges = [[9000, 9099]]
+`,
+			wantCfg: landlock.V9.BestEffort().
+				DisableLoggingForOriginatingProcess().
+				EnableLoggingForSubprocesses().
+				DisableLoggingForSubdomains(),
+			wantRules: []landlock.Rule{
+				landlock.RODirs("/usr", "/etc"),
+				landlock.RWDirs("/tmp").WithRefer().WithIoctlDev().WithResolveUnix(),
+				landlock.ROFiles("/a"),
+				landlock.RODirs("~/.config/*").Expand(),
+				landlock.RWFiles("/b").IgnoreIfMissing(),
+				landlock.PathAccess(ll.AccessFSReadFile|ll.AccessFSReadDir, "/c"),
+				landlock.ConnectTCP(53),
+				landlock.ConnectTCP(443),
+				landlock.BindTCP(8080),
+				landlock.BindTCPRange(9000, 9099),
+			},
+		},
+		{
+			name:    "Table",
+			policy:  "version = 1\nhandled_access_fs = [\"read_file\"]\n\n[logging]\nenable_for_subprocesses = true\n",
+			wantCfg: landlock.MustConfig(landlock.AccessFSSet(ll.AccessFSReadFile)).EnableLoggingForSubprocesses(),
+		},
+		{
+			name:    "InlineTable",
+			policy:  "version = 1\nabi = 2\nlogging = { enable_for_subprocesses = true, \"disable_for_subdomains\" = false }\n",
+			wantCfg: landlock.V2.EnableLoggingForSubprocesses(),
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, rules, err := Load(strings.NewReader(tt.policy))
+			if err != nil {
+				t.Fatalf("Load(): %v", err)
+			}
+			if cfg != tt.wantCfg {
+				t.Errorf("cfg = %v, want %v", cfg, tt.wantCfg)
+			}
+			if got, want := fmt.Sprint(rules), fmt.Sprint(tt.wantRules); got != want {
+				t.Errorf("rules = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		policy     string
+		wantLine   int
+		wantColumn int
+		wantField  string
+		anyField   bool
+		wantErr    string
+	}{
+		{
+			name:    "MissingVersion",
+			policy:  "abi = 1\n",
+			wantErr: "missing policy version",
+		},
+		{
+			name:       "SyntaxError",
+			policy:     "version = 1\nabi = 9 9\n",
+			wantLine:   2,
+			wantColumn: 8,
+			wantErr:    "expected a top-level item to end",
+		},
+		{
+			name:       "UnterminatedString",
+			policy:     "version = 1\n[[fs]]\nrule = \"ro_dirs\n",
+			wantLine:   3,
+			wantColumn: 16,
+			wantErr:    "strings cannot contain newlines",
+		},
+		{
+			name:     "DuplicateKey",
+			policy:   "version = 1\nabi = 9\nabi = 8\n",
+			wantLine: 3,
+			wantErr:  "already been defined",
+		},
+		{
+			name:      "Float",
+			policy:    "version = 1.0\n",
+			wantField: "version",
+			wantErr:   "floats, dates and times are not supported",
+		},
+		{
+			name:      "Date",
+			policy:    "version = 1\nabi = 9\n\n[[fs]]\nrule = \"ro_dirs\"\npaths = [1979-05-27]\n",
+			wantField: "fs[0].paths[0]",
+			wantErr:   "floats, dates and times are not supported",
+		},
+		{
+			name:      "UnknownField",
+			policy:    "version = 1\nabi = 9\n\n[[fs]]\nrule = \"ro_dirs\"\npaths = [\"/\"]\n\n[[net]]\nrule = \"bind_tcp\"\n  paths = [\"/\"]\n",
+			wantField: "net[0].paths",
+			wantErr:   "unknown field",
+		},
+		{
+			name:     "WrongType",
+			policy:   "version = 1\nabi = 9\n\n[[net]]\nrule = \"bind_tcp\"\nports = [80, 70000]\n",
+			anyField: true,
+			wantErr:  "cannot unmarshal",
+		},
+		{
+			name:      "UnknownAccessRight",
+			policy:    "version = 1\nabi = 9\n\n[[fs]]\nrule = \"path_access\"\npaths = [\"/\"]\naccess = [\"read_file\", \"red_file\"]\n",
+			wantField: "fs[0].access[1]",
+			wantErr:   `unknown access right "red_file"`,
+		},
+		{
+			name:      "ReversedPortRange",
+			policy:    "version = 1\nabi = 4\n\n[[net]]\nrule = \"bind_tcp\"\nport_ranges = [[90, 80]]\n",
+			wantField: "net[0].port_ranges[0]",
+			wantErr:   "invalid port range",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Load(strings.NewReader(tt.policy))
+			var pe *landlock.PolicyError
+			if !errors.As(err, &pe) {
+				t.Fatalf("Load() = %v, want *landlock.PolicyError", err)
+			}
+			if pe.Line != tt.wantLine {
+				t.Errorf("Line = %v, want %v (err: %v)", pe.Line, tt.wantLine, err)
+			}
+			if tt.wantColumn != 0 && pe.Column != tt.wantColumn {
+				t.Errorf("Column = %v, want %v (err: %v)", pe.Column, tt.wantColumn, err)
+			}
+			if !tt.anyField && pe.Field != tt.wantField {
+				t.Errorf("Field = %q, want %q (err: %v)", pe.Field, tt.wantField, err)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %q, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package landlock
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/landlock-lsm/go-landlock/landlock/internal"
+	ll "github.com/landlock-lsm/go-landlock/landlock/syscall"
+)
+
+// erratumNames are the names of the Landlock errata, indexed by the
+// erratum ID minus one, which is also the bit in the errata bitmask.
+var erratumNames = []string{
+	"tcp_socket_identification",
+	"scoped_signal_same_tgid",
+	"disconnected_directories",
+}
+
+// ProbeResult describes the Landlock support of the running kernel.
+// It is returned by [Probe].
+type ProbeResult struct {
+	// KernelABI is the Landlock ABI version reported by the kernel,
+	// or 0 if Landlock is not available.
+	KernelABI int `json:"kernel_abi"`
+
+	// ABI is the Landlock ABI version which Go-Landlock uses on the
+	// running kernel.  It differs from KernelABI if the kernel
+	// supports a newer version than Go-Landlock knows about, or if
+	// a version is not used because of a missing bug fix.
+	ABI int `json:"abi"`
+
+	// Errata is the bitmask of Landlock errata which are fixed in
+	// the running kernel, and ErrataNames are their names.
+	// Unknown errata are named "erratum_<ID>".
+	Errata      int      `json:"errata"`
+	ErrataNames []string `json:"errata_names"`
+
+	// The access rights, scopes and restriction flags which
+	// Go-Landlock can restrict on the running kernel.
+	SupportedAccessFS  AccessFSSet  `json:"supported_access_fs"`
+	SupportedAccessNet AccessNetSet `json:"supported_access_net"`
+	SupportedScoped    ScopedSet    `json:"supported_scoped"`
+	SupportedFlags     []string     `json:"supported_flags"`
+
+	// TSync is true if the kernel can enforce a ruleset on all
+	// threads of the process at once (Landlock ABI V8 and higher),
+	// so that Go-Landlock does not need libpsx for that.
+	TSync bool `json:"tsync"`
+
+	// LSMs is the list of active Linux Security Modules from
+	// /sys/kernel/security/lsm, or nil if it could not be read.
+	// InLSMList is true if "landlock" is one of them.
+	LSMs      []string `json:"lsms"`
+	InLSMList bool     `json:"in_lsm_list"`
+
+	// Audit is true if the kernel supports Landlock audit logging
+	// (Landlock ABI V7 and higher, and a kernel with audit support).
+	Audit bool `json:"audit"`
+}
+
+// Probe inspects the Landlock support of the running kernel.
+//
+// Probe does not restrict the calling process.  To find out whether
+// the process is already in a Landlock domain, use [CurrentStatus].
+func Probe() *ProbeResult {
+	res := &ProbeResult{}
+	if v, err := ll.LandlockGetABIVersion(); err == nil {
+		res.KernelABI = v
+	}
+	if errata, err := ll.LandlockGetErrata(); err == nil {
+		res.Errata = errata
+		res.ErrataNames = errataNames(errata)
+	}
+
+	v := internal.DetectedABIVersion()
+	if v >= len(abiInfos) {
+		v = len(abiInfos) - 1
+	}
+	abi := abiInfos[v]
+	res.ABI = abi.version
+	res.SupportedAccessFS = abi.supportedAccessFS
+	res.SupportedAccessNet = abi.supportedAccessNet
+	res.SupportedScoped = abi.supportedScoped
+	res.SupportedFlags = abi.supportedRestrictFlags.names()
+	res.TSync = abi.version >= 8
+
+	if data, err := os.ReadFile("/sys/kernel/security/lsm"); err == nil {
+		res.LSMs = strings.Split(strings.TrimSpace(string(data)), ",")
+		for _, lsm := range res.LSMs {
+			if lsm == "landlock" {
+				res.InLSMList = true
+			}
+		}
+	}
+
+	// /proc/self/loginuid only exists in kernels with audit support.
+	if _, err := os.Stat("/proc/self/loginuid"); err == nil {
+		res.Audit = abi.version >= 7
+	}
+	return res
+}
+
+func errataNames(errata int) []string {
+	var names []string
+	for i := 0; i < 63; i++ {
+		if errata&(1<<i) == 0 {
+			continue
+		}
+		if i < len(erratumNames) {
+			names = append(names, erratumNames[i])
+		} else {
+			names = append(names, fmt.Sprintf("erratum_%d", i+1))
+		}
+	}
+	return names
+}
+
+// String returns a human-readable multi-line description of the
+// probe result.
+func (r *ProbeResult) String() string {
+	var b strings.Builder
+	yesNo := func(v bool) string {
+		if v {
+			return "yes"
+		}
+		return "no"
+	}
+	fmt.Fprintf(&b, "Kernel ABI: V%d\n", r.KernelABI)
+	fmt.Fprintf(&b, "Go-Landlock ABI: V%d\n", r.ABI)
+	fmt.Fprintf(&b, "Errata: %#x", r.Errata)
+	if len(r.ErrataNames) > 0 {
+		fmt.Fprintf(&b, " (%v)", strings.Join(r.ErrataNames, ","))
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "FS access rights: %v\n", r.SupportedAccessFS)
+	fmt.Fprintf(&b, "Network access rights: %v\n", r.SupportedAccessNet)
+	fmt.Fprintf(&b, "Scopes: %v\n", r.SupportedScoped)
+	fmt.Fprintf(&b, "Restrict flags: %v\n", strings.Join(r.SupportedFlags, ","))
+	fmt.Fprintf(&b, "TSYNC: %v\n", yesNo(r.TSync))
+	if r.LSMs == nil {
+		b.WriteString("LSMs: unknown\n")
+	} else {
+		fmt.Fprintf(&b, "LSMs: %v (landlock listed: %v)\n", strings.Join(r.LSMs, ","), yesNo(r.InLSMList))
+	}
+	fmt.Fprintf(&b, "Audit: %v\n", yesNo(r.Audit))
+	return b.String()
+}
@@ -0,0 +1,22 @@
+package landlock
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestErrataNames(t *testing.T) {
+	for _, tt := range []struct {
+		errata int
+		want   []string
+	}{
+		{0, nil},
+		{0x2, []string{"scoped_signal_same_tgid"}},
+		{0x5, []string{"tcp_socket_identification", "disconnected_directories"}},
+		{0x10, []string{"erratum_5"}},
+	} {
+		if got := errataNames(tt.errata); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("errataNames(%#x) = %v, want %v", tt.errata, got, tt.want)
+		}
+	}
+}
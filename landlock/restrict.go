@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"runtime"
+	"slices"
 	"syscall"
 
 	ll "github.com/landlock-lsm/go-landlock/landlock/syscall"
@@ -16,49 +17,99 @@ import (
 // current kernel's Landlock ABI level.
 //
 // It establishes that rule.compatibleWithConfig(c) and c.compatibleWithABI(abi).
-func downgrade(c Config, rules []Rule, abi abiInfo) (Config, []Rule) {
+//
+// If ok is false, one of the rules could not be downgraded and the
+// result falls back to "ABI V0" (do nothing).
+func downgrade(c Config, rules []Rule, abi abiInfo) (_ Config, _ []Rule, ok bool) {
 	c = c.restrictTo(abi)
 
 	resRules := make([]Rule, 0, len(rules))
 	for _, rule := range rules {
 		rule, ok := rule.downgrade(c)
 		if !ok {
-			return v0, nil // Use "ABI V0" (do nothing)
+			return v0, nil, false // Use "ABI V0" (do nothing)
 		}
 		resRules = append(resRules, rule)
 	}
-	return c, resRules
+	return c, resRules, true
 }
 
 // restrict is the actual implementation which sets up Landlock.
 func restrict(c Config, rules ...Rule) error {
+	rs, err := prepare(c, rules...)
+	if err != nil {
+		return err
+	}
+	defer rs.Close()
+
+	return rs.Enforce()
+}
+
+// prepare does the ABI downgrade and populates the kernel ruleset.
+func prepare(c Config, rules ...Rule) (*Ruleset, error) {
 	abi := getSupportedABIVersion()
+	rules = slices.Concat(rules, implicitRules(c, abi))
+	return newRuleset(c, rules, abi)
+}
+
+// prepareSingleThreaded is like prepare, but for rulesets which are
+// only going to be enforced on a single thread.
+func prepareSingleThreaded(c Config, rules ...Rule) (*Ruleset, error) {
+	return newRuleset(c, rules, getSupportedABIVersion())
+}
+
+// newRuleset populates the kernel ruleset for the given ABI.
+//
+// Unlike prepare, it does not add rules to work around libpsx
+// issues, so it is suitable for rulesets which are enforced by
+// a single thread only.
+func newRuleset(c Config, rules []Rule, abi abiInfo) (*Ruleset, error) {
 	useTsync := abi.version >= 8
-	if !useTsync {
-		// Work around https://github.com/landlock-lsm/go-landlock/issues/39
-		rules = maybeWorkaroundBug39(c, rules)
+
+	resolveCfg := c
+	if c.bestEffort {
+		resolveCfg = c.restrictTo(abi)
+	}
+	rules, err := resolveDynamicRules(resolveCfg, rules)
+	if err != nil {
+		return nil, err
 	}
 
 	// Check validity of rules early.
 	for _, rule := range rules {
 		if !rule.compatibleWithConfig(c) {
-			return fmt.Errorf("incompatible rule %v: %w", rule, unix.EINVAL)
+			return nil, &IncompatibleRuleError{Rule: rule}
 		}
 	}
 
+	requested := c
+	fellBack := false
 	if c.bestEffort {
-		c, rules = downgrade(c, rules, abi)
+		var ok bool
+		c, rules, ok = downgrade(c, rules, abi)
+		fellBack = !ok
 	}
 	if !c.compatibleWithABI(abi) {
-		return fmt.Errorf("missing kernel Landlock support. Got Landlock ABI v%v, wanted %v", abi.version, c)
+		return nil, &UnsupportedABIError{Have: abi.version, Want: c}
 	}
 
+	rs, err := populateRuleset(c, rules, useTsync)
+	if err != nil {
+		return nil, err
+	}
+	requested.reportDegradation(c, abi, fellBack)
+	return rs, nil
+}
+
+// populateRuleset creates the kernel ruleset for c and adds the rules to it.
+func populateRuleset(c Config, rules []Rule, useTsync bool) (*Ruleset, error) {
 	// TODO: This might be incorrect - the "refer" permission is
 	// always implicit, even in Landlock V1. So enabling Landlock
 	// on a Landlock V1 kernel without any handled access rights
 	// will still forbid linking files between directories.
 	if c.handledAccessFS.isEmpty() && c.handledAccessNet.isEmpty() && c.scoped.isEmpty() {
-		return nil // Success: Nothing to restrict.
+		// Success: Nothing to restrict.
+		return &Ruleset{fd: -1, cfg: c, useTsync: useTsync}, nil
 	}
 
 	rulesetAttr := ll.RulesetAttr{
@@ -75,26 +126,30 @@ func restrict(c Config, rules ...Rule) error {
 			err = errors.New("unknown flags, unknown access, or too small size")
 		}
 		// Bug, because these should have been caught up front with the ABI version check.
-		return bug(fmt.Errorf("landlock_create_ruleset: %w", err))
+		return nil, bug(fmt.Errorf("landlock_create_ruleset: %w", err))
 	}
-	defer syscall.Close(fd)
 
 	for _, rule := range rules {
 		if err := rule.addToRuleset(fd, c); err != nil {
-			return err
+			syscall.Close(fd)
+			return nil, err
 		}
 	}
+	return &Ruleset{fd: fd, cfg: c, useTsync: useTsync}, nil
+}
 
-	if !useTsync {
+// enforce enforces the ruleset on all OS threads of the process.
+func (r *Ruleset) enforce() error {
+	if !r.useTsync {
 		if err := ll.AllThreadsPrctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
 			// This prctl invocation should always work.
 			return bug(fmt.Errorf("prctl(PR_SET_NO_NEW_PRIVS): %v", err))
 		}
 
-		if err := ll.AllThreadsLandlockRestrictSelf(fd, uint32(c.flags)); err != nil {
+		if err := ll.AllThreadsLandlockRestrictSelf(r.fd, uint32(r.cfg.flags)); err != nil {
 			if errors.Is(err, syscall.E2BIG) {
 				// Other errors than E2BIG should never happen.
-				return fmt.Errorf("the maximum number of stacked rulesets is reached for the current thread: %w", err)
+				return fmt.Errorf("%w: %w", ErrMaxStackedRulesets, err)
 			}
 			return bug(fmt.Errorf("landlock_restrict_self: %w", err))
 		}
@@ -107,18 +162,15 @@ func restrict(c Config, rules ...Rule) error {
 	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
 		return bug(fmt.Errorf("prctl(PR_SET_NO_NEW_PRIVS): %v", err))
 	}
-	if err := ll.LandlockRestrictSelf(fd, uint32(c.flags)|ll.FlagRestrictSelfTSync); err != nil {
+	if err := ll.LandlockRestrictSelf(r.fd, uint32(r.cfg.flags)|ll.FlagRestrictSelfTSync); err != nil {
 		if errors.Is(err, syscall.E2BIG) {
-			return fmt.Errorf("the maximum number of stacked rulesets is reached for the current thread: %w", err)
+			return fmt.Errorf("%w: %w", ErrMaxStackedRulesets, err)
 		}
 		return bug(fmt.Errorf("landlock_restrict_self: %w", err))
 	}
 	return nil
 }
 
-// Denotes an error that should not have happened.
-// If such an error occurs anyway, please try upgrading the library
-// and file a bug to github.com/landlock-lsm/go-landlock if the issue persists.
-func bug(err error) error {
-	return fmt.Errorf("BUG(go-landlock): This should not have happened: %w", err)
+func closeFD(fd int) error {
+	return syscall.Close(fd)
 }
@@ -0,0 +1,138 @@
+package landlock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// execHelperEnv is the environment variable which instructs a
+// re-executed binary to enforce an inherited ruleset and then execute
+// the actual command.  See [Ruleset.ApplyToCmd] and [RunExecHelper].
+const execHelperEnv = "GO_LANDLOCK_EXEC_HELPER"
+
+// RunExecHelper enforces the inherited ruleset and executes the actual
+// command, if the current process was started from a command prepared
+// with [Config.ApplyToCmd] or [Ruleset.ApplyToCmd].  Otherwise, it
+// returns immediately.
+//
+// Programs which use ApplyToCmd need to call RunExecHelper at the
+// start of their main function (or TestMain, in tests).  The helper is
+// opt-in, so that programs which merely link the landlock package do
+// not act on the environment of the process.
+//
+// RunExecHelper does not return in the helper process.  If the
+// command can not be executed, it exits with status 127.
+func RunExecHelper() {
+	v, ok := os.LookupEnv(execHelperEnv)
+	if !ok {
+		return
+	}
+	os.Unsetenv(execHelperEnv)
+
+	err := runExecHelper(v)
+	fmt.Fprintf(os.Stderr, "go-landlock: %v\n", err)
+	os.Exit(127)
+}
+
+// ApplyToCmd modifies cmd so that the started child process runs
+// under a Landlock ruleset built from c and the given rules.  The
+// calling process itself is not restricted.
+//
+// This is a shorthand for calling [Config.Prepare] followed by
+// [Ruleset.ApplyToCmd].
+//
+// Example:
+//
+//	cmd := exec.Command("/usr/bin/cc", "-c", "main.c")
+//	err := landlock.V9.BestEffort().ApplyToCmd(cmd,
+//	    landlock.RODirs("/usr", "/lib", "/etc"),
+//	    landlock.RWDirs(buildDir),
+//	)
+//	if err != nil {
+//	    log.Fatalf("landlock: %v", err)
+//	}
+//	err = cmd.Start()
+//	for _, f := range cmd.ExtraFiles {
+//	    f.Close()
+//	}
+func (c Config) ApplyToCmd(cmd *exec.Cmd, rules ...Rule) error {
+	if err := checkCmd(cmd); err != nil {
+		return err
+	}
+	rs, err := prepareSingleThreaded(c, rules...)
+	if err != nil {
+		return err
+	}
+	defer rs.Close()
+
+	return rs.ApplyToCmd(cmd)
+}
+
+// ApplyToCmd modifies cmd so that the started child process runs
+// under the Landlock ruleset.  The calling process itself is not
+// restricted, and the same ruleset can be applied to multiple
+// commands.
+//
+// The child process is started as a re-execution of the current
+// binary, which enforces the ruleset on itself in [RunExecHelper], and
+// then executes the command that cmd was configured with.  Therefore:
+//
+//   - The current binary must call [RunExecHelper] at the start of
+//     its main function.
+//   - All package initializers of the current binary also run in the
+//     child process, before the ruleset is enforced.
+//   - The ruleset must permit executing the command and loading its
+//     shared libraries.
+//   - cmd.Path is replaced with "/proc/self/exe", and the original
+//     path is passed to the child process in an environment variable.
+//     cmd.Args is unchanged, but cmd.String no longer reports the
+//     original command.  For the same reason, ApplyToCmd can only be
+//     called once per command, and not for commands which run
+//     "/proc/self/exe" themselves.
+//
+// The ruleset file descriptor is passed to the child process as an
+// additional entry in cmd.ExtraFiles.  As with the other entries, the
+// caller should close it once the command has been started.
+//
+// If enforcing the ruleset would be a no-op (e.g. when a best effort
+// configuration got downgraded on a kernel without Landlock support),
+// cmd is not modified.
+func (r *Ruleset) ApplyToCmd(cmd *exec.Cmd) error {
+	if err := checkCmd(cmd); err != nil {
+		return err
+	}
+	if r.closed {
+		return errRulesetClosed
+	}
+	if r.fd < 0 {
+		return nil // Nothing to restrict.
+	}
+	return r.applyToCmd(cmd)
+}
+
+// selfExe is the path under which the exec helper re-executes the
+// current binary.
+const selfExe = "/proc/self/exe"
+
+func checkCmd(cmd *exec.Cmd) error {
+	if cmd.Process != nil {
+		return errors.New("exec: already started")
+	}
+	if cmd.Err != nil {
+		return cmd.Err
+	}
+	// The exec helper would re-execute the current binary instead of
+	// the intended command in these cases.
+	if cmd.Path == selfExe {
+		return fmt.Errorf("can not apply a ruleset to a command running %v", selfExe)
+	}
+	for _, kv := range cmd.Env {
+		if strings.HasPrefix(kv, execHelperEnv+"=") {
+			return errors.New("a ruleset was already applied to the command")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+//go:build linux
+
+package landlock
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+
+	ll "github.com/landlock-lsm/go-landlock/landlock/syscall"
+	"golang.org/x/sys/unix"
+)
+
+func (r *Ruleset) applyToCmd(cmd *exec.Cmd) error {
+	fd, err := unix.FcntlInt(uintptr(r.fd), unix.F_DUPFD_CLOEXEC, 0)
+	if err != nil {
+		return fmt.Errorf("dup ruleset fd: %w", err)
+	}
+	f := os.NewFile(uintptr(fd), "landlock-ruleset")
+
+	// The child's file descriptor number is 3+i for cmd.ExtraFiles[i].
+	childFD := 3 + len(cmd.ExtraFiles)
+	cmd.ExtraFiles = append(cmd.ExtraFiles, f)
+
+	env := cmd.Env
+	if env == nil {
+		env = os.Environ()
+	}
+	helperArg := fmt.Sprintf("%d,%d,%s", childFD, r.cfg.flags, cmd.Path)
+	cmd.Env = append(env, execHelperEnv+"="+helperArg)
+	cmd.Path = selfExe
+	return nil
+}
+
+// runExecHelper enforces the inherited ruleset and executes the
+// actual command.  It only returns on error.
+//
+// The argument has the form "FD,FLAGS,PATH".
+func runExecHelper(arg string) error {
+	parts := strings.SplitN(arg, ",", 3)
+	if len(parts) != 3 {
+		return fmt.Errorf("malformed %v value %q", execHelperEnv, arg)
+	}
+	fd, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return fmt.Errorf("malformed ruleset fd %q: %w", parts[0], err)
+	}
+	flags, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		return fmt.Errorf("malformed restrict flags %q: %w", parts[1], err)
+	}
+	path := parts[2]
+
+	// execve(2) only retains the calling thread, so it is
+	// sufficient to enforce the ruleset on the current thread.
+	runtime.LockOSThread()
+
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return bug(fmt.Errorf("prctl(PR_SET_NO_NEW_PRIVS): %v", err))
+	}
+	if err := ll.LandlockRestrictSelf(fd, uint32(flags)); err != nil {
+		return fmt.Errorf("landlock_restrict_self: %w", err)
+	}
+	syscall.Close(fd)
+
+	if err := syscall.Exec(path, os.Args, os.Environ()); err != nil {
+		return fmt.Errorf("exec %v: %w", path, err)
+	}
+	return nil // unreachable
+}
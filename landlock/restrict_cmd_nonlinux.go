@@ -0,0 +1,16 @@
+//go:build !linux
+
+package landlock
+
+import (
+	"errors"
+	"os/exec"
+)
+
+func (r *Ruleset) applyToCmd(cmd *exec.Cmd) error {
+	return nil // unreachable, r.fd is always -1
+}
+
+func runExecHelper(arg string) error {
+	return errors.New("Landlock is only supported on Linux")
+}
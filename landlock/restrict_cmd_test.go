@@ -0,0 +1,108 @@
+//go:build linux
+
+package landlock_test
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/landlock-lsm/go-landlock/landlock"
+	"github.com/landlock-lsm/go-landlock/landlock/lltest"
+	ll "github.com/landlock-lsm/go-landlock/landlock/syscall"
+)
+
+func TestMain(m *testing.M) {
+	landlock.RunExecHelper()
+	if os.Getenv(mainGoroutineEnv) != "" {
+		if err := runOnMainGoroutine(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func TestApplyToCmd(t *testing.T) {
+	lltest.RequireABI(t, 1)
+
+	dir := t.TempDir()
+	pathRO := filepath.Join(dir, "ro")
+	pathNoAccess := filepath.Join(dir, "noaccess")
+	MustWriteFile(t, pathRO)
+	MustWriteFile(t, pathNoAccess)
+
+	cfg := landlock.MustConfig(landlock.AccessFSSet(ll.AccessFSReadFile))
+	rules := []landlock.Rule{
+		landlock.ROFiles("/bin", "/usr", "/lib", "/lib64", "/etc").IgnoreIfMissing(),
+		landlock.ROFiles(pathRO),
+	}
+
+	for _, tt := range []struct {
+		path    string
+		wantErr bool
+	}{
+		{path: pathRO, wantErr: false},
+		{path: pathNoAccess, wantErr: true},
+	} {
+		cmd := exec.Command("cat", tt.path)
+		if err := cfg.ApplyToCmd(cmd, rules...); err != nil {
+			t.Fatalf("ApplyToCmd(): %v", err)
+		}
+		var out strings.Builder
+		cmd.Stdout, cmd.Stderr = &out, &out
+		err := cmd.Start()
+		for _, f := range cmd.ExtraFiles {
+			f.Close()
+		}
+		if err == nil {
+			err = cmd.Wait()
+		}
+		if gotErr := err != nil; gotErr != tt.wantErr {
+			t.Errorf("cat %v: got error %v (output %q), want error: %v", tt.path, err, out.String(), tt.wantErr)
+		}
+	}
+
+	// The parent process stays unrestricted.
+	if err := openForRead(pathNoAccess); err != nil {
+		t.Errorf("openForRead(%q) in parent: %v", pathNoAccess, err)
+	}
+}
+
+func TestApplyToCmdAlreadyStarted(t *testing.T) {
+	cmd := exec.Command("true")
+	if err := cmd.Run(); err != nil {
+		t.Skipf("could not run true: %v", err)
+	}
+	if err := landlock.V1.BestEffort().ApplyToCmd(cmd); err == nil {
+		t.Errorf("ApplyToCmd() on started command succeeded, want error")
+	}
+}
+
+func TestApplyToCmdTwice(t *testing.T) {
+	lltest.RequireABI(t, 1)
+
+	cmd := exec.Command("/bin/true")
+	if err := landlock.V1.ApplyToCmd(cmd, landlock.RODirs("/")); err != nil {
+		t.Fatalf("ApplyToCmd(): %v", err)
+	}
+	defer func() {
+		for _, f := range cmd.ExtraFiles {
+			f.Close()
+		}
+	}()
+	if err := landlock.V1.ApplyToCmd(cmd, landlock.RODirs("/")); err == nil {
+		t.Errorf("second ApplyToCmd() succeeded, want error")
+	}
+}
+
+func TestApplyToCmdSelfExe(t *testing.T) {
+	cmd := exec.Command("/proc/self/exe")
+	if err := landlock.V1.BestEffort().ApplyToCmd(cmd); err == nil {
+		t.Errorf("ApplyToCmd() on /proc/self/exe succeeded, want error")
+	}
+}
@@ -0,0 +1,111 @@
+//go:build linux
+
+package landlock
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/landlock-lsm/go-landlock/landlock/lltest"
+	ll "github.com/landlock-lsm/go-landlock/landlock/syscall"
+)
+
+func TestOnDegrade(t *testing.T) {
+	lltest.RequireABI(t, 3)
+
+	for _, tt := range []struct {
+		name      string
+		cfg       Config
+		rules     []Rule
+		abi       int
+		wantCalls int
+		wantEvent DegradeEvent
+	}{
+		{
+			name: "NotDegraded",
+			cfg:  V3.BestEffort(),
+			abi:  3,
+		},
+		{
+			name:      "DroppedRights",
+			cfg:       V9.BestEffort().EnableLoggingForSubprocesses(),
+			rules:     []Rule{RODirs("/")},
+			abi:       3,
+			wantCalls: 1,
+			wantEvent: DegradeEvent{
+				ABI:              3,
+				DroppedAccessFS:  ll.AccessFSIoctlDev | ll.AccessFSResolveUnix,
+				DroppedAccessNet: V9.handledAccessNet,
+				DroppedScoped:    V9.scoped,
+				DroppedFlags:     []string{"log_new_exec_on"},
+			},
+		},
+		{
+			name:      "FellBackToV0",
+			cfg:       V2.BestEffort(),
+			rules:     []Rule{RWDirs("/").WithRefer()},
+			abi:       1,
+			wantCalls: 1,
+			wantEvent: DegradeEvent{
+				ABI:             1,
+				DroppedAccessFS: V2.handledAccessFS,
+				FellBackToV0:    true,
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var events []DegradeEvent
+			cfg := tt.cfg.OnDegrade(func(ev DegradeEvent) {
+				events = append(events, ev)
+			})
+
+			rs, err := newRuleset(cfg, tt.rules, abiInfos[tt.abi])
+			if err != nil {
+				t.Fatalf("newRuleset(): %v", err)
+			}
+			rs.Close()
+
+			if len(events) != tt.wantCalls {
+				t.Fatalf("got %d OnDegrade calls, want %d", len(events), tt.wantCalls)
+			}
+			if tt.wantCalls == 0 {
+				return
+			}
+			got, want := events[0], tt.wantEvent
+			if got.ABI != want.ABI ||
+				got.DroppedAccessFS != want.DroppedAccessFS ||
+				got.DroppedAccessNet != want.DroppedAccessNet ||
+				got.DroppedScoped != want.DroppedScoped ||
+				!slices.Equal(got.DroppedFlags, want.DroppedFlags) ||
+				got.FellBackToV0 != want.FellBackToV0 {
+				t.Errorf("event = %v, want %v", got, want)
+			}
+			if got.Requested != cfg {
+				t.Errorf("event.Requested = %v, want %v", got.Requested, cfg)
+			}
+			if got.Effective != rs.Config() {
+				t.Errorf("event.Effective = %v, want %v", got.Effective, rs.Config())
+			}
+		})
+	}
+}
+
+func TestOnDegradeWithoutLandlock(t *testing.T) {
+	var events []DegradeEvent
+	cfg := V1.BestEffort().OnDegrade(func(ev DegradeEvent) {
+		events = append(events, ev)
+	})
+
+	rs, err := newRuleset(cfg, []Rule{RODirs("/")}, abiInfos[0])
+	if err != nil {
+		t.Fatalf("newRuleset(): %v", err)
+	}
+	rs.Close()
+
+	if len(events) != 1 {
+		t.Fatalf("got %d OnDegrade calls, want 1", len(events))
+	}
+	if ev := events[0]; ev.DroppedAccessFS != V1.handledAccessFS || ev.FellBackToV0 {
+		t.Errorf("event = %v, want dropped %v without V0 fallback", ev, V1.handledAccessFS)
+	}
+}
@@ -333,7 +333,10 @@ func TestDowngrade(t *testing.T) {
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
-			gotCfg, gotRules := downgrade(tc.cfg, tc.rules, abiInfos[tc.supportedABI])
+			gotCfg, gotRules, ok := downgrade(tc.cfg, tc.rules, abiInfos[tc.supportedABI])
+			if wantOK := tc.wantCfg != v0 || tc.wantRules != nil || len(tc.rules) == 0; ok != wantOK {
+				t.Errorf("ok: got %v, want %v", ok, wantOK)
+			}
 
 			gotCfg.bestEffort = false // ignored for comparison
 			if gotCfg != tc.wantCfg {
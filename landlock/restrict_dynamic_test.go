@@ -0,0 +1,50 @@
+//go:build linux
+
+package landlock_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/landlock-lsm/go-landlock/landlock"
+	"github.com/landlock-lsm/go-landlock/landlock/lltest"
+)
+
+func TestDynamicRule(t *testing.T) {
+	lltest.RunInSubprocess(t, func() {
+		lltest.RequireABI(t, 1)
+
+		dir := lltest.TempDir(t)
+		allowed := filepath.Join(dir, "allowed")
+		denied := filepath.Join(dir, "denied")
+		MustWriteFile(t, allowed)
+		MustWriteFile(t, denied)
+
+		rule := landlock.DynamicRule("discovered", func(c landlock.Config) ([]landlock.Rule, error) {
+			return []landlock.Rule{landlock.ROFiles(allowed)}, nil
+		})
+		if err := landlock.V1.RestrictPaths(rule); err != nil {
+			t.Fatalf("RestrictPaths(): %v", err)
+		}
+
+		if err := openForRead(allowed); err != nil {
+			t.Errorf("openForRead(allowed): %v", err)
+		}
+		if err := openForRead(denied); err == nil {
+			t.Errorf("openForRead(denied) successful, want error")
+		}
+	})
+}
+
+func TestDynamicRuleError(t *testing.T) {
+	lltest.RequireABI(t, 1)
+
+	errRegistry := errors.New("registry unavailable")
+	rule := landlock.DynamicRule("registry", func(landlock.Config) ([]landlock.Rule, error) {
+		return nil, errRegistry
+	})
+	if _, err := landlock.V1.Prepare(rule); !errors.Is(err, errRegistry) {
+		t.Errorf("Prepare() = %v, want error wrapping %v", err, errRegistry)
+	}
+}
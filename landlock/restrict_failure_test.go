@@ -11,6 +11,7 @@ import (
 
 	"github.com/landlock-lsm/go-landlock/landlock"
 	"github.com/landlock-lsm/go-landlock/landlock/lltest"
+	ll "github.com/landlock-lsm/go-landlock/landlock/syscall"
 	"golang.org/x/sys/unix"
 )
 
@@ -48,6 +49,13 @@ func TestPathDoesNotExist(t *testing.T) {
 	if !errors.Is(err, os.ErrNotExist) {
 		t.Errorf("expected 'not exist' error, got: %v", err)
 	}
+	var pathErr *landlock.PathRuleError
+	if !errors.As(err, &pathErr) {
+		t.Fatalf("expected *PathRuleError, got: %v", err)
+	}
+	if pathErr.Path != doesNotExistPath || pathErr.Errno != unix.ENOENT {
+		t.Errorf("got PathRuleError{Path: %q, Errno: %v}, want {Path: %q, Errno: %v}", pathErr.Path, pathErr.Errno, doesNotExistPath, unix.ENOENT)
+	}
 }
 
 func TestPathDoesNotExist_Ignored(t *testing.T) {
@@ -79,10 +87,15 @@ func TestRestrictingPlainFileWithDirectoryFlags(t *testing.T) {
 	if isGoLandlockBug(err) {
 		t.Errorf("should not be marked as a go-landlock bug, but was: %v", err)
 	}
+	var pathErr *landlock.PathRuleError
+	if !errors.As(err, &pathErr) || pathErr.Errno != unix.EINVAL {
+		t.Errorf("expected *PathRuleError with EINVAL, got: %v", err)
+	}
 }
 
 func isGoLandlockBug(err error) bool {
-	return strings.Contains(err.Error(), "BUG(go-landlock)")
+	var bugErr *landlock.BugError
+	return errors.As(err, &bugErr) || strings.Contains(err.Error(), "BUG(go-landlock)")
 }
 
 func TestEmptyAccessRights(t *testing.T) {
@@ -132,5 +145,28 @@ func TestReferNotPermittedInStrictV1(t *testing.T) {
 		if !strings.Contains(err.Error(), "incompatible rule") {
 			t.Errorf("expected a 'incompatible rule' error, got: %v", err)
 		}
+		var ruleErr *landlock.IncompatibleRuleError
+		if !errors.As(err, &ruleErr) {
+			t.Errorf("expected *IncompatibleRuleError, got: %v", err)
+		}
+	}
+}
+
+func TestUnsupportedABI(t *testing.T) {
+	v, err := ll.LandlockGetABIVersion()
+	if err == nil && v >= 9 {
+		t.Skipf("Requires a kernel without Landlock V9, got V%v", v)
+	}
+
+	err = landlock.V9.Restrict()
+	var abiErr *landlock.UnsupportedABIError
+	if !errors.As(err, &abiErr) {
+		t.Fatalf("expected *UnsupportedABIError, got: %v", err)
+	}
+	if abiErr.Have >= 9 {
+		t.Errorf("abiErr.Have = %v, want < 9", abiErr.Have)
+	}
+	if abiErr.Want != landlock.V9 {
+		t.Errorf("abiErr.Want = %v, want %v", abiErr.Want, landlock.V9)
 	}
 }
@@ -34,3 +34,14 @@ func (a restrictFlagsSet) isSubset(b restrictFlagsSet) bool {
 func (a restrictFlagsSet) intersect(b restrictFlagsSet) restrictFlagsSet {
 	return a & b
 }
+
+// names returns the names of the flags in the set.
+func (a restrictFlagsSet) names() []string {
+	var res []string
+	for i, flagName := range flagNames {
+		if a&(1<<i) != 0 {
+			res = append(res, flagName)
+		}
+	}
+	return res
+}
@@ -0,0 +1,192 @@
+//go:build linux
+
+package landlock_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/landlock-lsm/go-landlock/landlock"
+	"github.com/landlock-lsm/go-landlock/landlock/lltest"
+	ll "github.com/landlock-lsm/go-landlock/landlock/syscall"
+	"golang.org/x/sys/unix"
+)
+
+func TestFDAccess(t *testing.T) {
+	lltest.RunInSubprocess(t, func() {
+		lltest.RequireABI(t, 1)
+
+		dir := lltest.TempDir(t)
+		data := filepath.Join(dir, "data")
+		other := filepath.Join(dir, "other")
+		for _, d := range []string{data, other} {
+			if err := os.Mkdir(d, 0o700); err != nil {
+				t.Fatal(err)
+			}
+			MustWriteFile(t, filepath.Join(d, "file"))
+		}
+
+		dataDir, err := os.OpenFile(data, unix.O_PATH|unix.O_DIRECTORY, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer dataDir.Close()
+
+		// The rule is bound to the open directory, not to its name.
+		moved := filepath.Join(dir, "moved")
+		if err := os.Rename(data, moved); err != nil {
+			t.Fatal(err)
+		}
+
+		rule := landlock.FDAccess(ll.AccessFSReadFile, dataDir)
+		if got := rule.String(); !strings.Contains(got, data) {
+			t.Errorf("String() = %q, want it to mention %q", got, data)
+		}
+		if err := landlock.V1.RestrictPaths(rule); err != nil {
+			t.Fatalf("RestrictPaths(): %v", err)
+		}
+
+		if err := openForRead(filepath.Join(moved, "file")); err != nil {
+			t.Errorf("openForRead(moved/file): %v", err)
+		}
+		if err := openForRead(filepath.Join(other, "file")); err == nil {
+			t.Errorf("openForRead(other/file) successful, want error")
+		}
+	})
+}
+
+func TestFDAccessClosedFile(t *testing.T) {
+	lltest.RequireABI(t, 1)
+
+	f, err := os.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	_, err = landlock.V1.Prepare(landlock.FDAccess(ll.AccessFSReadFile, f))
+	var pathErr *landlock.PathRuleError
+	if !errors.As(err, &pathErr) {
+		t.Errorf("Prepare() with closed file = %v, want PathRuleError", err)
+	}
+}
+
+func TestResolveBeneath(t *testing.T) {
+	lltest.RequireABI(t, 1)
+
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "root", "sub")
+	if err := os.MkdirAll(sub, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(dir, filepath.Join(sub, "escape")); err != nil {
+		t.Fatal(err)
+	}
+	root, err := os.Open(filepath.Join(dir, "root"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer root.Close()
+
+	for _, tt := range []struct {
+		path    string
+		wantErr error
+	}{
+		{path: "sub"},
+		{path: "sub/../sub"},
+		{path: "..", wantErr: syscall.EXDEV},
+		{path: "sub/escape", wantErr: syscall.EXDEV},
+		{path: dir, wantErr: syscall.EXDEV},
+		{path: "missing", wantErr: syscall.ENOENT},
+	} {
+		t.Run(tt.path, func(t *testing.T) {
+			rs, err := landlock.V1.Prepare(landlock.RODirs(tt.path).ResolveBeneath(root))
+			if err == nil {
+				rs.Close()
+			}
+			if !errEqual(err, tt.wantErr) {
+				t.Errorf("Prepare(RODirs(%q).ResolveBeneath(root)) = %v, want %v", tt.path, err, tt.wantErr)
+			}
+		})
+	}
+
+	// Missing paths can still be ignored.
+	rs, err := landlock.V1.Prepare(landlock.RODirs("missing").ResolveBeneath(root).IgnoreIfMissing())
+	if err != nil {
+		t.Errorf("Prepare() with IgnoreIfMissing: %v", err)
+	} else {
+		rs.Close()
+	}
+}
+
+func TestResolveNoSymlinks(t *testing.T) {
+	lltest.RequireABI(t, 1)
+
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target")
+	link := filepath.Join(dir, "link")
+	if err := os.Mkdir(target, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		path    string
+		wantErr error
+	}{
+		{path: target},
+		{path: link, wantErr: syscall.ELOOP},
+		{path: filepath.Join(link, "."), wantErr: syscall.ELOOP},
+	} {
+		rs, err := landlock.V1.Prepare(landlock.RODirs(tt.path).ResolveNoSymlinks())
+		if err == nil {
+			rs.Close()
+		}
+		if !errEqual(err, tt.wantErr) {
+			t.Errorf("Prepare(RODirs(%q).ResolveNoSymlinks()) = %v, want %v", tt.path, err, tt.wantErr)
+		}
+	}
+}
+
+func TestNoFollow(t *testing.T) {
+	lltest.RequireABI(t, 1)
+
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target")
+	link := filepath.Join(dir, "link")
+	if err := os.Mkdir(target, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+	// Only the final component is checked.
+	viaLink := filepath.Join(dir, "via")
+	if err := os.Symlink(dir, viaLink); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		path    string
+		wantErr error
+	}{
+		{path: target},
+		{path: filepath.Join(viaLink, "target")},
+		{path: link, wantErr: syscall.ELOOP},
+		{path: filepath.Join(dir, "missing"), wantErr: syscall.ENOENT},
+	} {
+		rs, err := landlock.V1.Prepare(landlock.RODirs(tt.path).NoFollow())
+		if err == nil {
+			rs.Close()
+		}
+		if !errEqual(err, tt.wantErr) {
+			t.Errorf("Prepare(RODirs(%q).NoFollow()) = %v, want %v", tt.path, err, tt.wantErr)
+		}
+	}
+}
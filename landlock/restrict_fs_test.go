@@ -314,3 +314,29 @@ func OSRelease(t testing.TB) (major, minor, patch int) {
 	}
 	return major, minor, patch
 }
+
+func TestRestrictExpandedPaths(t *testing.T) {
+	lltest.RunInSubprocess(t, func() {
+		lltest.RequireABI(t, 1)
+
+		dir := lltest.TempDir(t)
+		for _, name := range []string{"lib1", "lib2", "other"} {
+			MustWriteFile(t, filepath.Join(dir, name))
+		}
+		os.Setenv("LANDLOCK_TEST_DIR", dir)
+
+		rule := landlock.ROFiles("${LANDLOCK_TEST_DIR}/lib*").Expand()
+		if err := landlock.V1.RestrictPaths(rule); err != nil {
+			t.Fatalf("RestrictPaths(): %v", err)
+		}
+
+		for _, name := range []string{"lib1", "lib2"} {
+			if err := openForRead(filepath.Join(dir, name)); err != nil {
+				t.Errorf("openForRead(%v): %v", name, err)
+			}
+		}
+		if err := openForRead(filepath.Join(dir, "other")); err == nil {
+			t.Errorf("openForRead(other) successful, want error")
+		}
+	})
+}
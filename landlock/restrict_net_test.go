@@ -121,6 +121,42 @@ func TestRestrictNet(t *testing.T) {
 			WantConnectErr: syscall.EACCES,
 			WantBindErr:    syscall.EACCES,
 		},
+		{
+			Name:        "PermitPortRanges",
+			RequiredABI: 4,
+			EnableLandlock: func() error {
+				return landlock.V4.RestrictNet(
+					landlock.BindTCPRange(bPort-10, bPort+10),
+					landlock.ConnectTCPRange(cPort, cPort-1),
+				)
+			},
+			WantConnectErr: nil,
+			WantBindErr:    nil,
+		},
+		{
+			Name:        "PermitPortRangesNotCoveringPorts",
+			RequiredABI: 4,
+			EnableLandlock: func() error {
+				return landlock.V4.RestrictNet(
+					landlock.BindTCPRange(bPort+1, bPort+10),
+					landlock.ConnectTCPRange(cPort-10, cPort-1),
+				)
+			},
+			WantConnectErr: syscall.EACCES,
+			WantBindErr:    syscall.EACCES,
+		},
+		{
+			Name:        "PermitPortSets",
+			RequiredABI: 4,
+			EnableLandlock: func() error {
+				return landlock.V4.RestrictNet(
+					landlock.BindTCPPorts(80, bPort, 80),
+					landlock.ConnectTCPPorts(cPort+1, cPort, 443),
+				)
+			},
+			WantConnectErr: nil,
+			WantBindErr:    nil,
+		},
 	} {
 		t.Run(tt.Name, func(t *testing.T) {
 			lltest.RunInSubprocess(t, func() {
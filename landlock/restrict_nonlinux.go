@@ -2,11 +2,40 @@
 
 package landlock
 
-import "fmt"
-
 func restrict(c Config, rules ...Rule) error {
+	rs, err := prepare(c, rules...)
+	if err != nil {
+		return err
+	}
+	defer rs.Close()
+
+	return rs.Enforce()
+}
+
+func prepare(c Config, rules ...Rule) (*Ruleset, error) {
 	if c.bestEffort {
-		return nil // Fallback to "nothing"
+		c.reportDegradation(v0, abiInfos[0], true)
+		return &Ruleset{fd: -1, cfg: v0}, nil // Fallback to "nothing"
 	}
-	return fmt.Errorf("missing kernel Landlock support. Landlock is only supported on Linux")
+	return nil, &UnsupportedABIError{Have: 0, Want: c}
+}
+
+func prepareSingleThreaded(c Config, rules ...Rule) (*Ruleset, error) {
+	return prepare(c, rules...)
+}
+
+func (r *Ruleset) enforce() error {
+	return nil // unreachable, r.fd is always -1
+}
+
+func closeFD(fd int) error {
+	return nil // unreachable, r.fd is always -1
+}
+
+func (r *Ruleset) enforceCurrentThread() error {
+	return nil // unreachable, r.fd is always -1
+}
+
+func isMainThread() bool {
+	return false
 }
@@ -3,6 +3,7 @@
 package landlock_test
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
@@ -18,6 +19,22 @@ func TestRestrictNonLinux_BestEffort(t *testing.T) {
 	}
 }
 
+func TestRestrictNonLinux_OnDegrade(t *testing.T) {
+	var events []landlock.DegradeEvent
+	cfg := landlock.V3.BestEffort().OnDegrade(func(ev landlock.DegradeEvent) {
+		events = append(events, ev)
+	})
+	if err := cfg.RestrictPaths(landlock.RODirs("/")); err != nil {
+		t.Fatalf("RestrictPaths(): %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("got %d degrade events, want 1", len(events))
+	}
+	if ev := events[0]; !ev.FellBackToV0 || ev.DroppedAccessFS != cfg.HandledAccessFS() {
+		t.Errorf("degrade event = %+v, want fallback to V0 dropping %v", ev, cfg.HandledAccessFS())
+	}
+}
+
 func TestRestrictNonLinux_Strict(t *testing.T) {
 	err := landlock.V3.RestrictPaths(
 		landlock.RODirs("/"),
@@ -26,4 +43,8 @@ func TestRestrictNonLinux_Strict(t *testing.T) {
 	if !strings.Contains(err.Error(), errStr) {
 		t.Errorf("expected error with %q, got %v", errStr, err)
 	}
+	var abiErr *landlock.UnsupportedABIError
+	if !errors.As(err, &abiErr) || abiErr.Have != 0 {
+		t.Errorf("expected *UnsupportedABIError with Have=0, got %v", err)
+	}
 }
@@ -0,0 +1,93 @@
+//go:build linux
+
+package landlock_test
+
+import (
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/landlock-lsm/go-landlock/landlock"
+	"github.com/landlock-lsm/go-landlock/landlock/lltest"
+	ll "github.com/landlock-lsm/go-landlock/landlock/syscall"
+	"golang.org/x/sys/unix"
+)
+
+func TestProbe(t *testing.T) {
+	lltest.RequireABI(t, 1)
+
+	res := landlock.Probe()
+	if res.ABI < 1 || res.KernelABI < res.ABI {
+		t.Errorf("Probe() ABI = %v, KernelABI = %v", res.ABI, res.KernelABI)
+	}
+	if res.SupportedAccessFS == 0 {
+		t.Errorf("Probe().SupportedAccessFS is empty")
+	}
+}
+
+func TestProbeDoesNotRestrict(t *testing.T) {
+	lltest.RunInSubprocess(t, func() {
+		lltest.RequireABI(t, 1)
+
+		landlock.Probe()
+		if _, err := os.ReadFile("/proc/self/status"); err != nil {
+			t.Fatalf("ReadFile() after Probe(): %v", err)
+		}
+		if s := landlock.CurrentStatus(); s.Enforced() {
+			t.Errorf("CurrentStatus() = %+v after Probe(), want no domain", s)
+		}
+	})
+}
+
+func TestCurrentStatus(t *testing.T) {
+	lltest.RunInSubprocess(t, func() {
+		lltest.RequireABI(t, 1)
+
+		s := landlock.CurrentStatus()
+		if s.Depth != 0 || s.Enforced() || s.Remaining() != 16 {
+			t.Fatalf("CurrentStatus() before enforcement = %+v", s)
+		}
+		for want := 1; want <= 3; want++ {
+			if err := landlock.V1.RestrictPaths(landlock.RODirs("/")); err != nil {
+				t.Fatalf("RestrictPaths(): %v", err)
+			}
+			s := landlock.CurrentStatus()
+			if s.Depth != want || !s.Enforced() || s.Remaining() != 16-want {
+				t.Errorf("CurrentStatus() after %d enforcements = %+v", want, s)
+			}
+		}
+	})
+}
+
+// CurrentStatus needs to detect domains even if Go-Landlock does not
+// use the kernel's ABI version, e.g. with the landlocktsync build tag
+// on kernels before ABI V8.
+func TestCurrentStatusKernelABI(t *testing.T) {
+	lltest.RunInSubprocess(t, func() {
+		if v, err := ll.LandlockGetABIVersion(); err != nil || v < 1 {
+			t.Skipf("Requires kernel Landlock ABI >= V1, got V%v (err=%v)", v, err)
+		}
+
+		// Restrict all threads without Go-Landlock.
+		attr := ll.RulesetAttr{HandledAccessFS: ll.AccessFSMakeFifo}
+		fd, err := ll.LandlockCreateRuleset(&attr, 0)
+		if err != nil {
+			t.Fatalf("LandlockCreateRuleset(): %v", err)
+		}
+		defer syscall.Close(fd)
+		_, _, errno := syscall.AllThreadsSyscall6(unix.SYS_PRCTL, unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0, 0)
+		if errno == syscall.ENOTSUP {
+			t.Skip("AllThreadsSyscall is not supported with cgo")
+		}
+		if errno != 0 {
+			t.Fatalf("prctl(PR_SET_NO_NEW_PRIVS): %v", errno)
+		}
+		if _, _, errno := syscall.AllThreadsSyscall(unix.SYS_LANDLOCK_RESTRICT_SELF, uintptr(fd), 0, 0); errno != 0 {
+			t.Fatalf("landlock_restrict_self: %v", errno)
+		}
+
+		if s := landlock.CurrentStatus(); s.Depth != 1 {
+			t.Errorf("CurrentStatus() = %+v, want Depth 1", s)
+		}
+	})
+}
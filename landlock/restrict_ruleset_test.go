@@ -0,0 +1,108 @@
+//go:build linux
+
+package landlock_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/landlock-lsm/go-landlock/landlock"
+	"github.com/landlock-lsm/go-landlock/landlock/lltest"
+)
+
+func TestPrepareThenEnforce(t *testing.T) {
+	lltest.RunInSubprocess(t, func() {
+		lltest.RequireABI(t, 1)
+
+		dir := lltest.TempDir(t)
+		pathRO := filepath.Join(dir, "ro")
+		pathNoAccess := filepath.Join(dir, "noaccess")
+		MustWriteFile(t, pathRO)
+		MustWriteFile(t, pathNoAccess)
+
+		rs, err := landlock.V1.Prepare(landlock.ROFiles(pathRO))
+		if err != nil {
+			t.Fatalf("Prepare(): %v", err)
+		}
+		defer rs.Close()
+
+		if rs.FD() < 0 {
+			t.Errorf("rs.FD() = %v, want valid file descriptor", rs.FD())
+		}
+
+		// Not enforced yet.
+		if err := openForRead(pathNoAccess); err != nil {
+			t.Errorf("openForRead(%q) before Enforce(): %v", pathNoAccess, err)
+		}
+
+		// The path is resolved during Prepare() and may disappear afterwards.
+		if err := os.Rename(dir, dir+".moved"); err != nil {
+			t.Fatalf("os.Rename: %v", err)
+		}
+		pathRO = filepath.Join(dir+".moved", "ro")
+		pathNoAccess = filepath.Join(dir+".moved", "noaccess")
+
+		if err := rs.Enforce(); err != nil {
+			t.Fatalf("Enforce(): %v", err)
+		}
+
+		if err := openForRead(pathRO); err != nil {
+			t.Errorf("openForRead(%q): %v", pathRO, err)
+		}
+		if err := openForRead(pathNoAccess); err == nil {
+			t.Errorf("openForRead(%q) successful, want error", pathNoAccess)
+		}
+	})
+}
+
+func TestPrepareMissingPath(t *testing.T) {
+	lltest.RequireABI(t, 1)
+
+	doesNotExistPath := filepath.Join(t.TempDir(), "does_not_exist")
+
+	rs, err := landlock.V1.Prepare(landlock.RODirs(doesNotExistPath))
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected 'not exist' error, got: %v", err)
+	}
+	if rs != nil {
+		t.Errorf("Prepare() returned a ruleset despite error")
+	}
+}
+
+func TestEnforceAfterClose(t *testing.T) {
+	lltest.RequireABI(t, 1)
+
+	rs, err := landlock.V1.Prepare()
+	if err != nil {
+		t.Fatalf("Prepare(): %v", err)
+	}
+	if err := rs.Close(); err != nil {
+		t.Errorf("Close(): %v", err)
+	}
+	if err := rs.Close(); err != nil {
+		t.Errorf("second Close(): %v", err)
+	}
+	if rs.FD() != -1 {
+		t.Errorf("rs.FD() = %v after Close(), want -1", rs.FD())
+	}
+	if err := rs.Enforce(); err == nil {
+		t.Errorf("Enforce() after Close() succeeded, want error")
+	}
+}
+
+func TestPrepareBestEffortNoop(t *testing.T) {
+	rs, err := landlock.MustConfig().BestEffort().Prepare()
+	if err != nil {
+		t.Fatalf("Prepare(): %v", err)
+	}
+	defer rs.Close()
+
+	if rs.FD() != -1 {
+		t.Errorf("rs.FD() = %v for empty config, want -1", rs.FD())
+	}
+	if err := rs.Enforce(); err != nil {
+		t.Errorf("Enforce(): %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+//go:build linux
+
+package landlock_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/landlock-lsm/go-landlock/landlock"
+	"github.com/landlock-lsm/go-landlock/landlock/lltest"
+)
+
+func TestStagesEnter(t *testing.T) {
+	lltest.RunInSubprocess(t, func() {
+		lltest.RequireABI(t, 1)
+
+		dir := lltest.TempDir(t)
+		config := filepath.Join(dir, "config")
+		data := filepath.Join(dir, "data")
+		MustWriteFile(t, config)
+		MustWriteFile(t, data)
+
+		stages, err := landlock.NewStages(
+			landlock.Stage{Name: "init", Config: landlock.V1, Rules: []landlock.Rule{landlock.RODirs(dir)}},
+			landlock.Stage{Name: "serve", Config: landlock.V1, Rules: []landlock.Rule{landlock.ROFiles(data)}},
+			landlock.Stage{Name: "drain", Config: landlock.V1},
+		)
+		if err != nil {
+			t.Fatalf("NewStages(): %v", err)
+		}
+		defer stages.Close()
+
+		if got := stages.Current(); got != "" {
+			t.Errorf("Current() = %q, want empty", got)
+		}
+
+		if err := stages.Enter("serve"); err != nil {
+			t.Fatalf("Enter(serve): %v", err)
+		}
+		if got := stages.Current(); got != "serve" {
+			t.Errorf("Current() = %q, want %q", got, "serve")
+		}
+		if got := stages.Depth(); got != 1 {
+			t.Errorf("Depth() = %v, want 1", got)
+		}
+		if err := openForRead(data); err != nil {
+			t.Errorf("openForRead(%q): %v", data, err)
+		}
+		if err := openForRead(config); err == nil {
+			t.Errorf("openForRead(%q) successful, want error", config)
+		}
+
+		if err := stages.Enter("init"); err == nil {
+			t.Errorf("Enter(init) after serve succeeded, want error")
+		}
+		if err := stages.Enter("unknown"); err == nil {
+			t.Errorf("Enter(unknown) succeeded, want error")
+		}
+
+		if err := stages.Enter("drain"); err != nil {
+			t.Fatalf("Enter(drain): %v", err)
+		}
+		if got := stages.Depth(); got != 2 {
+			t.Errorf("Depth() = %v, want 2", got)
+		}
+		if err := openForRead(data); err == nil {
+			t.Errorf("openForRead(%q) successful, want error", data)
+		}
+	})
+}
+
+func TestStagesMaxDepth(t *testing.T) {
+	lltest.RunInSubprocess(t, func() {
+		lltest.RequireABI(t, 1)
+
+		var decl []landlock.Stage
+		for i := range 16 {
+			decl = append(decl, landlock.Stage{Name: string(rune('a' + i)), Config: landlock.V1})
+		}
+		stages, err := landlock.NewStages(decl...)
+		if err != nil {
+			t.Fatalf("NewStages(): %v", err)
+		}
+		defer stages.Close()
+
+		for _, st := range decl {
+			if err := stages.Enter(st.Name); err != nil {
+				t.Fatalf("Enter(%q): %v", st.Name, err)
+			}
+		}
+		if got := stages.Depth(); got != 16 {
+			t.Errorf("Depth() = %v, want 16", got)
+		}
+
+		// A further domain can not be stacked.
+		err = landlock.V1.Restrict()
+		if !errors.Is(err, landlock.ErrMaxStackedRulesets) {
+			t.Errorf("Restrict() at depth 16 = %v, want %v", err, landlock.ErrMaxStackedRulesets)
+		}
+	})
+}
+
+func TestStagesPreexistingDomains(t *testing.T) {
+	lltest.RunInSubprocess(t, func() {
+		lltest.RequireABI(t, 1)
+
+		stages, err := landlock.NewStages(
+			landlock.Stage{Name: "init", Config: landlock.V1},
+			landlock.Stage{Name: "serve", Config: landlock.V1},
+		)
+		if err != nil {
+			t.Fatalf("NewStages(): %v", err)
+		}
+		defer stages.Close()
+
+		// Leave room for exactly one more domain.
+		for range 15 {
+			if err := landlock.V1.Restrict(); err != nil {
+				t.Fatalf("Restrict(): %v", err)
+			}
+		}
+
+		if err := stages.Enter("init"); err != nil {
+			t.Fatalf("Enter(init): %v", err)
+		}
+		if err := stages.Enter("serve"); !errors.Is(err, landlock.ErrMaxStackedRulesets) {
+			t.Errorf("Enter(serve) = %v, want %v", err, landlock.ErrMaxStackedRulesets)
+		}
+		if got := stages.Current(); got != "init" {
+			t.Errorf("Current() = %q, want %q", got, "init")
+		}
+		if got := stages.Depth(); got != 1 {
+			t.Errorf("Depth() = %v, want 1", got)
+		}
+	})
+}
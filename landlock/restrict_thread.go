@@ -0,0 +1,105 @@
+package landlock
+
+import "runtime"
+
+// RestrictCurrentThread locks the calling goroutine to its current OS
+// thread with [runtime.LockOSThread] and restricts only that OS
+// thread, unlike [Config.Restrict], which restricts all threads of
+// the process.  Best effort mode and the handling of rules work the
+// same way as for [Config.Restrict].
+//
+// This is meant for dedicated OS threads which run less trusted code,
+// such as plugins, within a process that needs to stay unrestricted
+// otherwise.  Consider the caveats:
+//
+//   - The calling goroutine must stay locked to the OS thread for as
+//     long as the thread exists.  Do not call [runtime.UnlockOSThread]
+//     afterwards: The Go runtime would then schedule other goroutines
+//     on the restricted thread, and the calling goroutine on
+//     unrestricted threads.  When a locked goroutine exits, the Go
+//     runtime terminates its OS thread, except for the process's main
+//     thread, which stays around restricted.  Do not call
+//     RestrictCurrentThread from the main goroutine, which usually
+//     runs on the main thread.  [RunRestricted] takes care of this.
+//   - Goroutines started by the restricted goroutine run on other OS
+//     threads, and are therefore not restricted.
+//   - Operations which the Go runtime or libraries delegate to other
+//     threads are not restricted either.  For example, DNS lookups
+//     through cgo may run on a different thread.
+//   - Landlock only restricts system calls.  The restricted code shares
+//     the memory and the open file descriptors of the whole process,
+//     so this does not protect against malicious code.  Use
+//     [Config.ApplyToCmd] to restrict code in a separate process
+//     instead.
+//
+// The "no new privileges" flag is only set for the current OS thread
+// as well.
+//
+// Most errors, such as incompatible rules or missing paths, happen
+// before the OS thread is modified.  In that case, the goroutine is
+// unlocked again and can continue normally.  If the error wraps
+// [ErrMaxStackedRulesets] or is a [*BugError], the OS thread may
+// already have the "no new privileges" flag, so the goroutine may
+// stay locked, and it should exit without calling
+// [runtime.UnlockOSThread].
+func (c Config) RestrictCurrentThread(rules ...Rule) error {
+	runtime.LockOSThread()
+
+	rs, err := prepareSingleThreaded(c, rules...)
+	if err != nil {
+		runtime.UnlockOSThread()
+		return err
+	}
+	defer rs.Close()
+
+	if rs.fd < 0 {
+		return nil // Success: Nothing to restrict.
+	}
+	return rs.enforceCurrentThread()
+}
+
+// RunRestricted runs fn on a new OS thread which is restricted with
+// [Config.RestrictCurrentThread] and terminated after fn returns.
+// RunRestricted waits for fn to return.  It may be called from any
+// goroutine, including the main goroutine; fn never runs on the
+// process's main thread.
+//
+// If the restriction can not be enforced, fn is not run and the error
+// is returned.  The caveats of [Config.RestrictCurrentThread] apply;
+// in particular, goroutines started by fn are not restricted.
+func RunRestricted(cfg Config, rules []Rule, fn func()) (err error) {
+	runOnThrowawayThread(func() {
+		if err = cfg.RestrictCurrentThread(rules...); err != nil {
+			return
+		}
+		fn()
+	})
+	return err
+}
+
+// runOnThrowawayThread runs fn on a new goroutine which is locked to
+// its OS thread, and waits for fn to return.  The goroutine exits
+// without unlocking the OS thread, so that the Go runtime terminates
+// the thread together with any restrictions which fn put on it.
+//
+// The Go runtime never terminates the process's main thread, but
+// parks it for good, so fn must not run there: Its restrictions would
+// stay in the process, and [Config.Restrict] would fail because the
+// main thread differs from the others.  When the goroutine gets
+// scheduled on the main thread (e.g. when called from the main
+// goroutine), it keeps the main thread occupied while fn runs on
+// another goroutine, and then releases it unmodified.
+func runOnThrowawayThread(fn func()) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		runtime.LockOSThread()
+		if isMainThread() {
+			runOnThrowawayThread(fn)
+			runtime.UnlockOSThread()
+			return
+		}
+		fn()
+	}()
+	<-done
+}
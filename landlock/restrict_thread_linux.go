@@ -0,0 +1,33 @@
+//go:build linux
+
+package landlock
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+
+	ll "github.com/landlock-lsm/go-landlock/landlock/syscall"
+	"golang.org/x/sys/unix"
+)
+
+// enforceCurrentThread enforces the ruleset on the current OS thread
+// only.  The caller needs to lock the goroutine to the OS thread.
+func (r *Ruleset) enforceCurrentThread() error {
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return bug(fmt.Errorf("prctl(PR_SET_NO_NEW_PRIVS): %v", err))
+	}
+	if err := ll.LandlockRestrictSelf(r.fd, uint32(r.cfg.flags)); err != nil {
+		if errors.Is(err, syscall.E2BIG) {
+			return fmt.Errorf("%w: %w", ErrMaxStackedRulesets, err)
+		}
+		return bug(fmt.Errorf("landlock_restrict_self: %w", err))
+	}
+	return nil
+}
+
+// isMainThread reports whether the calling goroutine runs on the
+// process's main OS thread.
+func isMainThread() bool {
+	return unix.Gettid() == unix.Getpid()
+}
@@ -0,0 +1,147 @@
+//go:build linux
+
+package landlock_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"testing"
+
+	"github.com/landlock-lsm/go-landlock/landlock"
+	"github.com/landlock-lsm/go-landlock/landlock/lltest"
+)
+
+func TestRunRestricted(t *testing.T) {
+	lltest.RunInSubprocess(t, func() {
+		lltest.RequireABI(t, 1)
+
+		dir := lltest.TempDir(t)
+		allowed := filepath.Join(dir, "allowed")
+		denied := filepath.Join(dir, "denied")
+		MustWriteFile(t, allowed)
+		MustWriteFile(t, denied)
+
+		var allowedErr, deniedErr, goroutineErr error
+		err := landlock.RunRestricted(landlock.V1, []landlock.Rule{landlock.ROFiles(allowed)}, func() {
+			allowedErr = openForRead(allowed)
+			deniedErr = openForRead(denied)
+
+			// Goroutines started by fn are not restricted.
+			done := make(chan struct{})
+			go func() {
+				goroutineErr = openForRead(denied)
+				close(done)
+			}()
+			<-done
+		})
+		if err != nil {
+			t.Fatalf("RunRestricted(): %v", err)
+		}
+		if allowedErr != nil {
+			t.Errorf("openForRead(allowed) in fn: %v", allowedErr)
+		}
+		if deniedErr == nil {
+			t.Errorf("openForRead(denied) in fn successful, want error")
+		}
+		if goroutineErr != nil {
+			t.Errorf("openForRead(denied) in goroutine started by fn: %v", goroutineErr)
+		}
+
+		// The rest of the process stays unrestricted.
+		if err := openForRead(denied); err != nil {
+			t.Errorf("openForRead(denied) outside of fn: %v", err)
+		}
+		if s := landlock.CurrentStatus(); s.Enforced() {
+			t.Errorf("CurrentStatus() = %+v, want unrestricted process", s)
+		}
+	})
+}
+
+func TestRunRestrictedError(t *testing.T) {
+	called := false
+	err := landlock.RunRestricted(landlock.V1, []landlock.Rule{landlock.ConnectTCP(53)}, func() {
+		called = true
+	})
+	var ire *landlock.IncompatibleRuleError
+	if !errors.As(err, &ire) {
+		t.Errorf("RunRestricted() = %v, want IncompatibleRuleError", err)
+	}
+	if called {
+		t.Errorf("fn was called despite the error")
+	}
+}
+
+// mainGoroutineEnv makes TestMain call runOnMainGoroutine instead of
+// running the tests.
+const mainGoroutineEnv = "LANDLOCK_TEST_MAIN_GOROUTINE"
+
+// runOnMainGoroutine uses the helpers which restrict throwaway OS
+// threads from the main goroutine, whose goroutines the Go runtime
+// tends to schedule on the main OS thread.  The main thread is never
+// terminated, so it needs to stay unaffected.
+func runOnMainGoroutine() error {
+	runtime.GOMAXPROCS(1)
+
+	landlock.CurrentStatus()
+	if err := landlock.RunRestricted(landlock.V1, []landlock.Rule{landlock.RODirs("/")}, func() {}); err != nil {
+		return fmt.Errorf("RunRestricted(): %w", err)
+	}
+
+	status, err := os.ReadFile(fmt.Sprintf("/proc/self/task/%d/status", os.Getpid()))
+	if err != nil {
+		return err
+	}
+	if !regexp.MustCompile(`(?m)^NoNewPrivs:\s+0$`).Match(status) {
+		return errors.New("the main thread has the no new privileges flag")
+	}
+	if err := landlock.V1.Restrict(landlock.RODirs("/")); err != nil {
+		return fmt.Errorf("Restrict(): %w", err)
+	}
+	return nil
+}
+
+func TestThreadHelpersOnMainGoroutine(t *testing.T) {
+	lltest.RequireABI(t, 1)
+
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	cmd.Env = append(os.Environ(), mainGoroutineEnv+"=1")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("%v: %v\n%s", cmd, err, out)
+	}
+}
+
+func TestRestrictCurrentThread(t *testing.T) {
+	lltest.RunInSubprocess(t, func() {
+		lltest.RequireABI(t, 1)
+
+		dir := lltest.TempDir(t)
+		denied := filepath.Join(dir, "denied")
+		MustWriteFile(t, denied)
+
+		result := make(chan error)
+		go func() {
+			if err := landlock.V1.RestrictCurrentThread(landlock.RODirs("/usr")); err != nil {
+				result <- err
+				return
+			}
+			// Yield, to check that the goroutine stays on its thread.
+			runtime.Gosched()
+			if err := openForRead(denied); err == nil {
+				result <- errors.New("openForRead(denied) on restricted thread successful, want error")
+				return
+			}
+			result <- nil
+		}()
+		if err := <-result; err != nil {
+			t.Error(err)
+		}
+		if err := openForRead(denied); err != nil {
+			t.Errorf("openForRead(denied) on other thread: %v", err)
+		}
+	})
+}
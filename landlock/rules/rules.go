@@ -0,0 +1,209 @@
+// Package rules provides reusable Landlock rules for common needs of
+// Go programs, such as DNS resolution or TLS certificate lookup.
+//
+// The rules are meant to work across Linux distributions.  They list
+// the paths used on the common distributions, and ignore the ones that
+// do not exist on the running system (see
+// [landlock.FSRule.IgnoreIfMissing]).  They only grant read access,
+// unless noted otherwise.
+//
+// Some of the rules include network rules, so they need to be enforced
+// with [landlock.Config.Restrict], which restricts both filesystem and
+// network access.
+//
+// Example:
+//
+//	err := landlock.V9.BestEffort().Restrict(
+//	    rules.DNSLookup(),
+//	    rules.TLSRoots(),
+//	    landlock.ConnectTCP(443),
+//	)
+package rules
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/landlock-lsm/go-landlock/landlock"
+)
+
+// DNSLookup bundles the rules required for host name resolution
+// through the Go resolver and through the C library's Name Service
+// Switch: the resolver and NSS configuration files, connecting to TCP
+// port 53, and the UNIX sockets of nscd(8) and systemd-resolved(8).
+//
+// DNS queries over UDP are not restricted by Landlock.
+//
+// This rule includes a network rule; see the package documentation.
+func DNSLookup() landlock.Rule {
+	return landlock.CompositeRule(
+		landlock.ROFiles(
+			"/etc/resolv.conf",
+			"/etc/nsswitch.conf",
+			"/etc/hosts",
+			"/etc/host.conf",
+			"/etc/gai.conf",
+			"/etc/services",
+			"/etc/protocols",
+		).IgnoreIfMissing(),
+		landlock.ROFiles(
+			"/run/systemd/resolve",
+			"/run/nscd",
+			"/var/run/nscd",
+		).WithResolveUnix().IgnoreIfMissing(),
+		landlock.ConnectTCP(53),
+	)
+}
+
+// x509CertFiles and x509CertDirs are the locations which Go's
+// crypto/x509 package probes for CA certificates on Linux.
+var (
+	x509CertFiles = []string{
+		"/etc/ssl/certs/ca-certificates.crt",                // Debian/Ubuntu/Gentoo etc.
+		"/etc/pki/tls/certs/ca-bundle.crt",                  // Fedora/RHEL 6
+		"/etc/ssl/ca-bundle.pem",                            // OpenSUSE
+		"/etc/pki/tls/cacert.pem",                           // OpenELEC
+		"/etc/pki/ca-trust/extracted/pem/tls-ca-bundle.pem", // CentOS/RHEL 7
+		"/etc/ssl/cert.pem",                                 // Alpine Linux
+	}
+	x509CertDirs = []string{
+		"/etc/ssl/certs",     // SLES10/SLES11
+		"/etc/pki/tls/certs", // Fedora/RHEL
+	}
+)
+
+// TLSRoots bundles the rules required for loading the system's CA
+// certificates, e.g. with [crypto/x509.SystemCertPool].
+//
+// It covers the certificate bundles and directories which Go's
+// crypto/x509 package probes, the locations pointed to by the
+// SSL_CERT_FILE and SSL_CERT_DIR environment variables at the time of
+// the call, and the directories in which distributions keep the
+// targets of certificate symlinks.
+func TLSRoots() landlock.Rule {
+	files := append([]string{}, x509CertFiles...)
+	dirs := append([]string{}, x509CertDirs...)
+	if f := os.Getenv("SSL_CERT_FILE"); f != "" {
+		files = append(files, f)
+	}
+	if d := os.Getenv("SSL_CERT_DIR"); d != "" {
+		dirs = append(dirs, filepath.SplitList(d)...)
+	}
+	dirs = append(dirs,
+		"/usr/share/ca-certificates",
+		"/usr/local/share/ca-certificates",
+		"/etc/ca-certificates",
+		"/etc/pki/ca-trust",
+		"/var/lib/ca-certificates",
+	)
+	return landlock.CompositeRule(
+		landlock.ROFiles(files...).IgnoreIfMissing(),
+		landlock.RODirs(dirs...).IgnoreIfMissing(),
+	)
+}
+
+// TimeZones bundles the rules required for loading time zone
+// information, e.g. with [time.LoadLocation].
+//
+// It covers the local time zone configuration, the time zone
+// databases which Go's time package probes, and the location pointed
+// to by the ZONEINFO environment variable at the time of the call.
+func TimeZones() landlock.Rule {
+	files := []string{"/etc/localtime", "/etc/timezone"}
+	if z := os.Getenv("ZONEINFO"); z != "" {
+		files = append(files, z)
+	}
+	return landlock.CompositeRule(
+		landlock.ROFiles(files...).IgnoreIfMissing(),
+		landlock.RODirs(
+			"/usr/share/zoneinfo",
+			"/usr/share/lib/zoneinfo",
+			"/usr/lib/locale/TZ",
+			"/etc/zoneinfo",
+		).IgnoreIfMissing(),
+	)
+}
+
+// Locale bundles the rules required by the C library for locale
+// support: the locale configuration, compiled locale data, message
+// catalogs and character set conversion modules.
+func Locale() landlock.Rule {
+	return landlock.CompositeRule(
+		landlock.ROFiles(
+			"/etc/locale.conf",
+			"/etc/default/locale",
+			"/etc/locale.alias",
+		).IgnoreIfMissing(),
+		landlock.RODirs(
+			"/usr/lib/locale",
+			"/usr/share/locale",
+			"/usr/share/i18n",
+			"/usr/lib/gconv",
+			"/usr/lib64/gconv",
+			"/usr/lib/x86_64-linux-gnu/gconv",
+			"/usr/lib/aarch64-linux-gnu/gconv",
+		).IgnoreIfMissing(),
+	)
+}
+
+// Devices bundles the rules for the commonly used pseudo devices:
+// Reading from and writing to /dev/null, /dev/zero and /dev/full,
+// and reading from /dev/random and /dev/urandom.
+func Devices() landlock.Rule {
+	return landlock.CompositeRule(
+		landlock.RWFiles("/dev/null", "/dev/zero", "/dev/full").IgnoreIfMissing(),
+		landlock.ROFiles("/dev/random", "/dev/urandom").IgnoreIfMissing(),
+	)
+}
+
+// SharedLibraries bundles the rules required for running dynamically
+// linked programs: the dynamic linker, its configuration and cache,
+// and the standard library directories.
+//
+// This also permits executing the files in these directories.
+func SharedLibraries() landlock.Rule {
+	return landlock.CompositeRule(
+		landlock.ROFiles(
+			"/etc/ld.so.cache",
+			"/etc/ld.so.conf",
+			"/etc/ld.so.preload",
+		).IgnoreIfMissing(),
+		landlock.RODirs(
+			"/etc/ld.so.conf.d",
+			"/lib",
+			"/lib32",
+			"/lib64",
+			"/libx32",
+			"/usr/lib",
+			"/usr/lib32",
+			"/usr/lib64",
+			"/usr/libx32",
+			"/usr/local/lib",
+		).IgnoreIfMissing(),
+	)
+}
+
+// GoRuntime bundles the rules for files which the Go runtime and
+// standard library read about the current process and the system:
+// the process' own /proc/self directory, the CPU and memory limits of
+// its cgroup, and transparent huge page settings.
+//
+// The /proc/self directory is resolved at the time when the rule is
+// enforced, so it does not grant access to the /proc directories of
+// child processes.
+func GoRuntime() landlock.Rule {
+	return landlock.CompositeRule(
+		landlock.RODirs(
+			"/proc/self",
+			"/sys/fs/cgroup",
+			"/sys/kernel/mm/transparent_hugepage",
+		).IgnoreIfMissing(),
+		landlock.ROFiles(
+			"/proc/sys/kernel/osrelease",
+			"/proc/sys/net/core/somaxconn",
+			"/proc/stat",
+			"/proc/meminfo",
+			"/proc/cpuinfo",
+		).IgnoreIfMissing(),
+	)
+}
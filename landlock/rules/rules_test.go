@@ -0,0 +1,67 @@
+//go:build linux
+
+package rules_test
+
+import (
+	"crypto/x509"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/landlock-lsm/go-landlock/landlock"
+	"github.com/landlock-lsm/go-landlock/landlock/lltest"
+	"github.com/landlock-lsm/go-landlock/landlock/rules"
+)
+
+var allRules = map[string]func() landlock.Rule{
+	"DNSLookup":       rules.DNSLookup,
+	"TLSRoots":        rules.TLSRoots,
+	"TimeZones":       rules.TimeZones,
+	"Locale":          rules.Locale,
+	"Devices":         rules.Devices,
+	"SharedLibraries": rules.SharedLibraries,
+	"GoRuntime":       rules.GoRuntime,
+}
+
+func TestRulesExplain(t *testing.T) {
+	for name, rule := range allRules {
+		t.Run(name, func(t *testing.T) {
+			if _, err := landlock.V9.BestEffort().Explain(rule()); err != nil {
+				t.Errorf("Explain(%v): %v", name, err)
+			}
+		})
+	}
+}
+
+func TestRulesEnforced(t *testing.T) {
+	lltest.RunInSubprocess(t, func() {
+		lltest.RequireABI(t, 1)
+
+		err := landlock.V9.BestEffort().Restrict(
+			rules.TimeZones(),
+			rules.TLSRoots(),
+			rules.Devices(),
+		)
+		if err != nil {
+			t.Fatalf("Restrict(): %v", err)
+		}
+
+		if _, err := os.Stat("/usr/share/zoneinfo/Europe/Berlin"); err == nil {
+			if _, err := time.LoadLocation("Europe/Berlin"); err != nil {
+				t.Errorf("time.LoadLocation(): %v", err)
+			}
+		}
+		if _, err := x509.SystemCertPool(); err != nil {
+			t.Errorf("x509.SystemCertPool(): %v", err)
+		}
+		f, err := os.OpenFile("/dev/null", os.O_WRONLY, 0)
+		if err != nil {
+			t.Errorf("Opening /dev/null for writing: %v", err)
+		} else {
+			f.Close()
+		}
+		if _, err := os.ReadFile("/etc/passwd"); err == nil {
+			t.Errorf("Reading /etc/passwd succeeded, want error")
+		}
+	})
+}
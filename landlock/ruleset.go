@@ -0,0 +1,100 @@
+package landlock
+
+import "errors"
+
+var errRulesetClosed = errors.New("the ruleset is already closed")
+
+// Ruleset is a Landlock ruleset which has been prepared up front and
+// which can be enforced at a later point in time.
+//
+// Preparing a ruleset resolves all the rules: In best effort mode,
+// the configuration is downgraded to what the running kernel
+// supports, all paths referenced by filesystem rules are opened, and
+// the resulting rules are added to a kernel-side ruleset.  Errors
+// about missing paths are therefore reported by [Config.Prepare],
+// whereas [Ruleset.Enforce] only reports errors about the
+// enforcement itself.
+//
+// A Ruleset holds a file descriptor and should be closed using
+// [Ruleset.Close] when it is no longer needed.
+type Ruleset struct {
+	// fd is the ruleset file descriptor, or -1 if enforcing the
+	// ruleset is a no-op (e.g. because of a best effort
+	// downgrade to "no Landlock support").
+	fd int
+
+	// cfg is the (possibly downgraded) config that the ruleset
+	// was created with.
+	cfg Config
+
+	// useTsync is true if enforcement should use the
+	// FlagRestrictSelfTSync flag instead of libpsx.
+	useTsync bool
+
+	closed bool
+}
+
+// Prepare resolves the given rules and builds a Landlock ruleset
+// from them, without enforcing it yet.
+//
+// The returned [Ruleset] can be enforced later using
+// [Ruleset.Enforce].  This is useful for programs which need to
+// resolve paths at startup (e.g. before they disappear or before
+// calling chroot(2)) but want to enforce the ruleset at a later,
+// well-defined point.
+//
+// Calling Prepare and Enforce in direct succession is equivalent to
+// calling [Config.Restrict].
+func (c Config) Prepare(rules ...Rule) (*Ruleset, error) {
+	return prepare(c, rules...)
+}
+
+// Enforce enforces the ruleset on all goroutines of the current
+// process, the same way as [Config.Restrict] does.
+//
+// Enforce also sets the "no new privileges" flag for all OS threads
+// managed by the Go runtime.
+//
+// It is possible to enforce the same ruleset multiple times.  Each
+// enforcement stacks an additional Landlock domain.
+func (r *Ruleset) Enforce() error {
+	if r.closed {
+		return errRulesetClosed
+	}
+	if r.fd < 0 {
+		return nil // Success: Nothing to restrict.
+	}
+	return r.enforce()
+}
+
+// FD returns the ruleset file descriptor, or -1 if the ruleset would
+// not restrict anything when enforced.
+//
+// The file descriptor stays owned by the Ruleset and gets closed by
+// [Ruleset.Close].
+func (r *Ruleset) FD() int {
+	if r.closed {
+		return -1
+	}
+	return r.fd
+}
+
+// Config returns the configuration which the ruleset is going to
+// enforce.  In best effort mode, this is the configuration after
+// downgrading it to what the running kernel supports.
+func (r *Ruleset) Config() Config {
+	return r.cfg
+}
+
+// Close releases the ruleset file descriptor.  A closed ruleset can
+// not be enforced any more.  It is safe to call Close multiple times.
+func (r *Ruleset) Close() error {
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	if r.fd < 0 {
+		return nil
+	}
+	return closeFD(r.fd)
+}
@@ -14,10 +14,44 @@ var scopedNames = []string{
 
 var supportedScoped = ScopedSet((1 << len(scopedNames)) - 1)
 
+var scopedSyntax = accessSetSyntax{
+	names:        scopedNames,
+	kernelPrefix: "LANDLOCK_SCOPE_",
+	aliases:      map[string]uint64{"all": uint64(supportedScoped)},
+}
+
 func (a ScopedSet) String() string {
 	return accessSetString(uint64(a), scopedNames)
 }
 
+// ParseScopedSet parses a set of IPC scopes.
+//
+// It accepts the format returned by [ScopedSet.String], such as
+// "{abstract_unix_socket,signal}" or "∅", as well as comma-separated
+// lists without braces.  The individual scopes may also be spelled
+// like the kernel constants (e.g. "LANDLOCK_SCOPE_SIGNAL"), and "all"
+// denotes all scopes supported by this version of Go-Landlock.
+func ParseScopedSet(s string) (ScopedSet, error) {
+	a, err := scopedSyntax.parse(s)
+	return ScopedSet(a), err
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (a ScopedSet) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+// It accepts the same format as [ParseScopedSet].
+func (a *ScopedSet) UnmarshalText(text []byte) error {
+	v, err := ParseScopedSet(string(text))
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
+
 func (a ScopedSet) isSubset(b ScopedSet) bool {
 	return a&b == a
 }
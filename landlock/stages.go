@@ -0,0 +1,171 @@
+package landlock
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// maxStackedRulesets is the maximum number of Landlock domains which
+// the kernel permits to stack on top of each other.
+const maxStackedRulesets = 16
+
+// Stage is a named sandboxing policy, for use with [NewStages].
+type Stage struct {
+	Name   string
+	Config Config
+	Rules  []Rule
+}
+
+// Stages is a sequence of increasingly restrictive sandboxing
+// policies, which a program transitions through during its lifetime.
+//
+// For example, a server might read its configuration and bind its
+// listening port in an "init" stage, serve requests in a "serve" stage
+// with read-only access to its data, and finish outstanding work in a
+// "drain" stage.
+//
+// Each stage is enforced as an additional Landlock domain on top of
+// the previous ones, which can only ever restrict the process further.
+// Stages is safe for concurrent use.
+type Stages struct {
+	mu       sync.Mutex
+	stages   []Stage
+	rulesets []*Ruleset
+	current  int // Index of the current stage, or -1.
+	depth    int // Number of Landlock domains enforced so far.
+}
+
+// NewStages declares the given stages, in the order in which they are
+// entered.  As each stage adds a Landlock domain, at most 16 stages
+// can be declared, the kernel limit for stacked Landlock domains.
+//
+// NewStages validates that each stage is a subset of the previous one:
+// It needs to restrict at least the same access rights and scopes, and
+// it may only grant access rights which the previous stage grants as
+// well.  Paths are compared lexically, so a path is considered to be
+// granted if it is equal to or beneath a path granted in the previous
+// stage.
+//
+// NewStages also prepares the rulesets for all stages up front, as
+// with [Config.Prepare], so that missing paths and unsupported
+// configurations are reported early.  The prepared rulesets are
+// released when the stages are entered, or with [Stages.Close].
+func NewStages(stages ...Stage) (*Stages, error) {
+	if len(stages) > maxStackedRulesets {
+		return nil, fmt.Errorf("%w: %d stages, at most %d are possible", ErrMaxStackedRulesets, len(stages), maxStackedRulesets)
+	}
+	seen := make(map[string]bool)
+	for i, st := range stages {
+		if st.Name == "" {
+			return nil, fmt.Errorf("stage %d: missing name", i)
+		}
+		if seen[st.Name] {
+			return nil, fmt.Errorf("duplicate stage name %q", st.Name)
+		}
+		seen[st.Name] = true
+		if i > 0 {
+			if err := checkSubStage(stages[i-1], st); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	s := &Stages{stages: stages, current: -1}
+	for _, st := range stages {
+		rs, err := st.Config.Prepare(st.Rules...)
+		if err != nil {
+			s.Close()
+			return nil, fmt.Errorf("stage %q: %w", st.Name, err)
+		}
+		s.rulesets = append(s.rulesets, rs)
+	}
+	return s, nil
+}
+
+// Enter enforces the stage with the given name.
+//
+// Stages can only be entered in the declared order, but stages may be
+// skipped.  If the kernel limit of 16 stacked Landlock domains is
+// already reached, including the domains which were enforced outside
+// of this Stages value, the kernel refuses the transition and Enter
+// returns an error wrapping [ErrMaxStackedRulesets].  The current
+// stage stays unchanged in that case.
+func (s *Stages) Enter(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx := -1
+	for i, st := range s.stages {
+		if st.Name == name {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return fmt.Errorf("unknown stage %q", name)
+	}
+	if idx <= s.current {
+		return fmt.Errorf("can not enter stage %q from stage %q", name, s.stages[s.current].Name)
+	}
+
+	rs := s.rulesets[idx]
+	if err := rs.Enforce(); err != nil {
+		return fmt.Errorf("stage %q: %w", name, err)
+	}
+	if rs.FD() >= 0 {
+		s.depth++
+	}
+	for i := s.current + 1; i <= idx; i++ {
+		s.rulesets[i].Close()
+	}
+	s.current = idx
+	return nil
+}
+
+// Current returns the name of the current stage, or the empty string
+// if no stage has been entered yet.
+func (s *Stages) Current() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.current < 0 {
+		return ""
+	}
+	return s.stages[s.current].Name
+}
+
+// Depth returns the number of Landlock domains which were enforced by
+// entering stages.  Stages which did not enforce anything (e.g. in
+// best effort mode on kernels without Landlock support) are not
+// counted, and neither are Landlock domains which were enforced
+// outside of this Stages value; use [CurrentStatus] for those.
+func (s *Stages) Depth() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.depth
+}
+
+// Close releases the prepared rulesets of the stages which have not
+// been entered yet.  Those stages can not be entered afterwards.
+func (s *Stages) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var errs []error
+	for _, rs := range s.rulesets[s.current+1:] {
+		errs = append(errs, rs.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// checkSubStage returns an error if next permits anything which prev
+// does not permit.
+func checkSubStage(prev, next Stage) error {
+	err := checkSubset(Policy{next.Config, next.Rules}, Policy{prev.Config, prev.Rules})
+	if err != nil {
+		return fmt.Errorf("stage %q is not a subset of stage %q: %w", next.Name, prev.Name, err)
+	}
+	return nil
+}
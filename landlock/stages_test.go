@@ -0,0 +1,107 @@
+package landlock
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckSubStage(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		prev    Stage
+		next    Stage
+		wantErr string
+	}{
+		{
+			name: "Narrower",
+			prev: Stage{Name: "init", Config: V5, Rules: []Rule{RWDirs("/srv"), BindTCP(80), ConnectTCP(5432)}},
+			next: Stage{Name: "serve", Config: V5, Rules: []Rule{RODirs("/srv/data"), ConnectTCP(5432)}},
+		},
+		{
+			name: "SamePolicy",
+			prev: Stage{Name: "a", Config: V5, Rules: []Rule{RODirs("/usr")}},
+			next: Stage{Name: "b", Config: V5, Rules: []Rule{RODirs("/usr")}},
+		},
+		{
+			name: "MoreHandledRights",
+			prev: Stage{Name: "a", Config: V1, Rules: []Rule{RWDirs("/tmp")}},
+			next: Stage{Name: "b", Config: V5, Rules: []Rule{RODirs("/tmp"), ConnectTCP(53)}},
+		},
+		{
+			name: "Composite",
+			prev: Stage{Name: "a", Config: V3, Rules: []Rule{CompositeRule(RODirs("/usr"), RWDirs("/tmp"))}},
+			next: Stage{Name: "b", Config: V3, Rules: []Rule{CompositeRule(ROFiles("/usr/lib"), RWFiles("/tmp/x"))}},
+		},
+		{
+			name:    "FewerHandledRights",
+			prev:    Stage{Name: "a", Config: V5},
+			next:    Stage{Name: "b", Config: V3},
+			wantErr: `stage "b" is not a subset of stage "a": does not restrict {ioctl_dev}`,
+		},
+		{
+			name:    "FewerScopes",
+			prev:    Stage{Name: "a", Config: V6},
+			next:    Stage{Name: "b", Config: V5},
+			wantErr: "does not restrict scopes",
+		},
+		{
+			name:    "WiderPath",
+			prev:    Stage{Name: "a", Config: V3, Rules: []Rule{RODirs("/usr/lib")}},
+			next:    Stage{Name: "b", Config: V3, Rules: []Rule{RODirs("/usr")}},
+			wantErr: `grants {execute,read_file,read_dir} on "/usr"`,
+		},
+		{
+			name:    "SimilarPrefix",
+			prev:    Stage{Name: "a", Config: V3, Rules: []Rule{RODirs("/usr")}},
+			next:    Stage{Name: "b", Config: V3, Rules: []Rule{RODirs("/usr2")}},
+			wantErr: `on "/usr2"`,
+		},
+		{
+			name:    "MoreRights",
+			prev:    Stage{Name: "a", Config: V3, Rules: []Rule{RODirs("/tmp")}},
+			next:    Stage{Name: "b", Config: V3, Rules: []Rule{RWDirs("/tmp")}},
+			wantErr: `on "/tmp"`,
+		},
+		{
+			name:    "NewPort",
+			prev:    Stage{Name: "a", Config: V4, Rules: []Rule{ConnectTCP(53)}},
+			next:    Stage{Name: "b", Config: V4, Rules: []Rule{ConnectTCP(53), BindTCP(53)}},
+			wantErr: "grants {bind_tcp} on TCP port 53",
+		},
+		{
+			name: "UnhandledInPrev",
+			prev: Stage{Name: "a", Config: V3, Rules: []Rule{RODirs("/usr")}},
+			next: Stage{Name: "b", Config: V4, Rules: []Rule{RODirs("/usr"), ConnectTCP(53)}},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkSubStage(tt.prev, tt.next)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkSubStage() = %v, want success", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkSubStage() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewStagesInvalid(t *testing.T) {
+	if _, err := NewStages(Stage{Name: "a", Config: V1}, Stage{Name: "a", Config: V1}); err == nil {
+		t.Errorf("NewStages() with duplicate names succeeded")
+	}
+	if _, err := NewStages(Stage{Config: V1}); err == nil {
+		t.Errorf("NewStages() with missing name succeeded")
+	}
+	var many []Stage
+	for i := range maxStackedRulesets + 1 {
+		many = append(many, Stage{Name: string(rune('a' + i)), Config: V1})
+	}
+	if _, err := NewStages(many...); !errors.Is(err, ErrMaxStackedRulesets) {
+		t.Errorf("NewStages() with %d stages = %v, want %v", len(many), err, ErrMaxStackedRulesets)
+	}
+}
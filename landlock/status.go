@@ -0,0 +1,66 @@
+package landlock
+
+import (
+	ll "github.com/landlock-lsm/go-landlock/landlock/syscall"
+)
+
+// Status describes the Landlock enforcement state of the current
+// process.  It is returned by [CurrentStatus].
+type Status struct {
+	// ABI is the Landlock ABI version which Go-Landlock uses on the
+	// running kernel, or 0 if Landlock is not available.
+	ABI int
+
+	// Depth is the number of stacked Landlock domains which the
+	// process is restricted by, or -1 if it could not be determined.
+	Depth int
+
+	// DomainIDs are the IDs of the process's Landlock domains, as
+	// used in audit records, from the innermost to the outermost
+	// domain.  The kernel does not currently expose them to the
+	// restricted process, so DomainIDs is always nil for now.
+	DomainIDs []uint64
+}
+
+// CurrentStatus returns whether and how deeply the current process is
+// restricted by Landlock.
+//
+// As the kernel does not expose the Landlock domains of a process
+// directly, CurrentStatus determines the depth by stacking Landlock
+// domains on a separate OS thread until the kernel limit of 16
+// stacked domains is reached.  The OS thread is terminated
+// afterwards, so the calling goroutine and the rest of the process
+// stay unaffected.  The result reflects the OS threads of the Go
+// runtime, which [Config.Restrict] restricts together.
+//
+// On kernels without Landlock support, the depth is 0.  The depth is
+// also determined if Go-Landlock does not use the kernel's Landlock
+// ABI version, e.g. when built with the landlocktsync tag on a kernel
+// before Landlock ABI V8, in which case ABI is 0.
+func CurrentStatus() *Status {
+	s := &Status{ABI: getSupportedABIVersion().version, Depth: -1}
+	if v, err := ll.LandlockGetABIVersion(); err != nil || v < 1 {
+		s.Depth = 0
+		return s
+	}
+	if depth, err := domainDepth(); err == nil {
+		s.Depth = depth
+	}
+	return s
+}
+
+// Enforced reports whether the process is restricted by at least one
+// Landlock domain.
+func (s *Status) Enforced() bool {
+	return s.Depth > 0
+}
+
+// Remaining returns the number of Landlock domains which can still be
+// stacked before the kernel limit is reached, e.g. with
+// [Config.Restrict] or [Stages.Enter], or -1 if that is unknown.
+func (s *Status) Remaining() int {
+	if s.Depth < 0 {
+		return -1
+	}
+	return maxStackedRulesets - s.Depth
+}
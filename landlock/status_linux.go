@@ -0,0 +1,52 @@
+//go:build linux
+
+package landlock
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+
+	ll "github.com/landlock-lsm/go-landlock/landlock/syscall"
+	"golang.org/x/sys/unix"
+)
+
+// domainDepth returns the number of Landlock domains which the current
+// thread is restricted by.
+//
+// It determines the depth by stacking Landlock domains on a throwaway
+// OS thread until the kernel limit is reached, so the calling thread
+// stays unaffected.
+func domainDepth() (depth int, err error) {
+	runOnThrowawayThread(func() {
+		depth, err = stackUntilFull()
+	})
+	return depth, err
+}
+
+// stackUntilFull enforces Landlock domains on the current OS thread
+// until the kernel limit is reached, and returns the number of
+// domains which existed beforehand.  It must only be called on an OS
+// thread which is terminated afterwards.
+func stackUntilFull() (int, error) {
+	attr := ll.RulesetAttr{HandledAccessFS: ll.AccessFSExecute}
+	fd, err := ll.LandlockCreateRuleset(&attr, 0)
+	if err != nil {
+		return 0, fmt.Errorf("landlock_create_ruleset: %w", err)
+	}
+	defer syscall.Close(fd)
+
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return 0, fmt.Errorf("prctl(PR_SET_NO_NEW_PRIVS): %w", err)
+	}
+	for i := 0; i <= maxStackedRulesets; i++ {
+		err := ll.LandlockRestrictSelf(fd, 0)
+		if errors.Is(err, syscall.E2BIG) {
+			return maxStackedRulesets - i, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("landlock_restrict_self: %w", err)
+		}
+	}
+	return 0, bug(errors.New("no limit for stacked Landlock domains"))
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package landlock
+
+import "errors"
+
+func domainDepth() (int, error) {
+	return 0, errors.New("Landlock is only supported on Linux")
+}
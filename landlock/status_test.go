@@ -0,0 +1,13 @@
+package landlock
+
+import "testing"
+
+func TestStatusUnknownDepth(t *testing.T) {
+	s := &Status{ABI: 3, Depth: -1}
+	if s.Enforced() {
+		t.Error("Enforced() = true for unknown depth")
+	}
+	if got := s.Remaining(); got != -1 {
+		t.Errorf("Remaining() = %v, want -1", got)
+	}
+}
@@ -0,0 +1,343 @@
+package worker
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/landlock-lsm/go-landlock/landlock"
+)
+
+// ErrCrashed is returned by [Pool.Call] when the worker process exits
+// or breaks the protocol while handling a request.
+var ErrCrashed = errors.New("worker process crashed")
+
+// ErrClosed is returned by [Pool.Call] after the pool was closed.
+var ErrClosed = errors.New("worker pool is closed")
+
+// TaskError is returned by [Pool.Call] when the task function returns
+// an error in the worker process.
+type TaskError struct {
+	Task string
+	Msg  string
+}
+
+func (e *TaskError) Error() string {
+	return fmt.Sprintf("task %q: %v", e.Task, e.Msg)
+}
+
+// Options configure a [Pool].
+type Options struct {
+	// Config and Rules are the Landlock restrictions of the worker
+	// processes.  The rights to execute the current binary and to
+	// load its shared libraries are added automatically.
+	Config landlock.Config
+	Rules  []landlock.Rule
+
+	// Size is the number of worker processes.  It defaults to
+	// runtime.GOMAXPROCS(0).
+	Size int
+
+	// Timeout limits the time which a worker may spend on a single
+	// request.  Workers which exceed it are killed and restarted.
+	// There is no limit if it is 0.
+	Timeout time.Duration
+
+	// Stderr receives the standard output and standard error of
+	// the worker processes.  It defaults to os.Stderr.
+	Stderr io.Writer
+}
+
+// Pool is a pool of sandboxed worker processes which run a [Task].
+// It is safe for concurrent use.
+type Pool[Req, Resp any] struct {
+	task string
+	opts Options
+	exe  string
+	rs   *landlock.Ruleset
+
+	// slots has one entry per worker.  A nil entry stands for a
+	// worker which needs to be (re)started.
+	slots chan *proc
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// proc is a running worker process.
+type proc struct {
+	cmd *exec.Cmd
+	in  io.WriteCloser // requests
+	out io.ReadCloser  // responses
+	w   *errWriter     // in, as written to by enc
+	enc *gob.Encoder
+	dec *gob.Decoder
+}
+
+// errWriter records the last error of writing to w.  It tells write
+// errors, which break the connection to the worker, apart from errors
+// which the gob encoder finds before writing a value.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (e *errWriter) Write(b []byte) (int, error) {
+	n, err := e.w.Write(b)
+	if err != nil {
+		e.err = err
+	}
+	return n, err
+}
+
+// NewPool starts a pool of worker processes for task.
+//
+// The Landlock ruleset is prepared once with [landlock.Config.Prepare]
+// and applied to each worker process with
+// [landlock.Ruleset.ApplyToCmd].  The workers are started up front, so
+// that problems with the restrictions are reported early.
+func NewPool[Req, Resp any](task *Task[Req, Resp], opts Options) (*Pool[Req, Resp], error) {
+	if opts.Size <= 0 {
+		opts.Size = runtime.GOMAXPROCS(0)
+	}
+	if opts.Stderr == nil {
+		opts.Stderr = os.Stderr
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("worker pool: %w", err)
+	}
+	deps, err := landlock.ExecutableDeps(exe)
+	if err != nil {
+		return nil, fmt.Errorf("worker pool: %w", err)
+	}
+	rules := append([]landlock.Rule{deps}, opts.Rules...)
+	rs, err := opts.Config.Prepare(rules...)
+	if err != nil {
+		return nil, fmt.Errorf("worker pool: %w", err)
+	}
+
+	p := &Pool[Req, Resp]{
+		task:  task.name,
+		opts:  opts,
+		exe:   exe,
+		rs:    rs,
+		slots: make(chan *proc, opts.Size),
+	}
+	for range opts.Size {
+		w, err := p.start()
+		if err != nil {
+			p.Close()
+			return nil, fmt.Errorf("worker pool: %w", err)
+		}
+		p.slots <- w
+	}
+	return p, nil
+}
+
+// start starts a new worker process.
+func (p *Pool[Req, Resp]) start() (*proc, error) {
+	reqR, reqW, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	respR, respW, err := os.Pipe()
+	if err != nil {
+		reqR.Close()
+		reqW.Close()
+		return nil, err
+	}
+
+	cmd := exec.Command(p.exe)
+	cmd.Stdout = p.opts.Stderr
+	cmd.Stderr = p.opts.Stderr
+	cmd.ExtraFiles = []*os.File{reqR, respW} // workerInFD, workerOutFD
+	// The parent closes its copies of the child's files, including
+	// the ruleset added by ApplyToCmd, once the child is started.
+	defer func() {
+		for _, f := range cmd.ExtraFiles {
+			f.Close()
+		}
+	}()
+	cmd.Env = append(os.Environ(), workerEnv+"="+p.task)
+	if err := p.rs.ApplyToCmd(cmd); err != nil {
+		reqW.Close()
+		respR.Close()
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		reqW.Close()
+		respR.Close()
+		return nil, err
+	}
+	w := &errWriter{w: reqW}
+	return &proc{
+		cmd: cmd,
+		in:  reqW,
+		out: respR,
+		w:   w,
+		enc: gob.NewEncoder(w),
+		dec: gob.NewDecoder(respR),
+	}, nil
+}
+
+// stop terminates the worker process.  If kill is false, the worker
+// is asked to exit by closing its input.
+func (w *proc) stop(kill bool) error {
+	if kill {
+		w.cmd.Process.Kill()
+	}
+	w.in.Close()
+	err := w.cmd.Wait()
+	w.out.Close()
+	return err
+}
+
+// Call sends req to an idle worker process and returns its response.
+//
+// Call waits for an idle worker until ctx is done.  The request is
+// limited by the deadline of ctx and by [Options.Timeout]; if either
+// is exceeded, the worker gets killed and Call returns the context
+// error.  If the worker crashes, Call returns an error wrapping
+// [ErrCrashed], and if the task returns an error, Call returns a
+// [*TaskError].  Crashed and killed workers are restarted for the
+// next request.  If req can not be encoded, Call returns the encoding
+// error, and the worker stays in use.
+func (p *Pool[Req, Resp]) Call(ctx context.Context, req Req) (Resp, error) {
+	var zero Resp
+
+	if p.isClosed() {
+		return zero, ErrClosed
+	}
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+	var w *proc
+	select {
+	case w = <-p.slots:
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+	if p.isClosed() {
+		p.release(w)
+		return zero, ErrClosed
+	}
+	// The select above picks randomly if both cases are ready.
+	if err := ctx.Err(); err != nil {
+		p.release(w)
+		return zero, err
+	}
+	if w == nil {
+		var err error
+		if w, err = p.start(); err != nil {
+			p.release(nil)
+			return zero, fmt.Errorf("restarting worker: %w", err)
+		}
+	}
+
+	if p.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.opts.Timeout)
+		defer cancel()
+	}
+
+	done := make(chan error, 1)
+	var rep reply[Resp]
+	var encErr error
+	go func() {
+		if err := w.enc.Encode(&req); err != nil {
+			if w.w.err == nil {
+				encErr = err
+			}
+			done <- err
+			return
+		}
+		done <- w.dec.Decode(&rep)
+	}()
+
+	select {
+	case err := <-done:
+		if encErr != nil {
+			// Nothing was sent for the request, so the worker
+			// is still usable.
+			p.release(w)
+			return zero, fmt.Errorf("task %q: encoding request: %w", p.task, encErr)
+		}
+		if err != nil {
+			werr := w.stop(true)
+			p.release(nil)
+			return zero, fmt.Errorf("task %q: %w: %w", p.task, ErrCrashed, errors.Join(err, werr))
+		}
+	case <-ctx.Done():
+		w.stop(true)
+		<-done
+		p.release(nil)
+		return zero, fmt.Errorf("task %q: %w", p.task, ctx.Err())
+	}
+
+	p.release(w)
+	if rep.Err != "" {
+		return zero, &TaskError{Task: p.task, Msg: rep.Err}
+	}
+	return rep.Value, nil
+}
+
+// release returns a worker slot to the pool, or stops the worker if
+// the pool was closed in the meantime.
+func (p *Pool[Req, Resp]) release(w *proc) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed && w != nil {
+		w.stop(false)
+		w = nil
+	}
+	p.slots <- w
+}
+
+func (p *Pool[Req, Resp]) isClosed() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.closed
+}
+
+// Close stops the worker processes.  It waits for the idle workers to
+// exit; workers which are busy with a request are stopped after they
+// are done.  Calls to [Pool.Call] after Close return [ErrClosed].
+func (p *Pool[Req, Resp]) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	p.mu.Unlock()
+
+	// Stop the idle workers and hand back empty slots, so that callers
+	// which are waiting for a slot return ErrClosed.
+	var errs []error
+	var idle int
+	for drained := false; !drained; {
+		select {
+		case w := <-p.slots:
+			if w != nil {
+				errs = append(errs, w.stop(false))
+			}
+			idle++
+		default:
+			drained = true
+		}
+	}
+	for range idle {
+		p.slots <- nil
+	}
+	errs = append(errs, p.rs.Close())
+	return errors.Join(errs...)
+}
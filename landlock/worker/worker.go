@@ -0,0 +1,171 @@
+// Package worker runs code which processes untrusted input in a pool
+// of sandboxed child processes.
+//
+// The child processes are re-executions of the current binary, which
+// are restricted with a Landlock [landlock.Config] and rules before
+// they start, using [landlock.Config.ApplyToCmd].  Requests and
+// responses are passed between the processes over pipes, encoded with
+// [encoding/gob].  A crashing child process only fails the request it
+// was working on, and gets restarted for the next request.
+//
+// A program declares its tasks as package-level variables and calls
+// [Main] at the start of its main function:
+//
+//	var parseTask = worker.NewTask("parse", func(data []byte) (*Document, error) {
+//	    return parse(data)
+//	})
+//
+//	func main() {
+//	    worker.Main() // Only returns in the parent process.
+//
+//	    pool, err := worker.NewPool(parseTask, worker.Options{
+//	        Config: landlock.V9.BestEffort(),
+//	    })
+//	    if err != nil {
+//	        log.Fatal(err)
+//	    }
+//	    defer pool.Close()
+//
+//	    doc, err := pool.Call(ctx, untrustedData)
+//	    // ...
+//	}
+//
+// Compared to restricting the whole process, as shown in
+// examples/go-landlock-convert, this is suitable for long-running
+// servers which need to process untrusted input on demand, while the
+// server itself stays unrestricted.
+package worker
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/landlock-lsm/go-landlock/landlock"
+)
+
+// workerEnv is the environment variable which instructs a
+// re-executed binary to run as a worker.  Its value is the name of
+// the task.
+const workerEnv = "GO_LANDLOCK_WORKER"
+
+// The file descriptors of the request and response pipes in the
+// worker process.  The pool passes the pipes as the first two extra
+// files of the worker command.
+const (
+	workerInFD  = 3
+	workerOutFD = 4
+)
+
+var (
+	tasksMu sync.Mutex
+	tasks   = make(map[string]func(r io.Reader, w io.Writer) error)
+)
+
+// Task is a function which can be run in worker processes.  It is
+// created with [NewTask].
+type Task[Req, Resp any] struct {
+	name string
+}
+
+// reply is the response to a request, as sent by the worker process.
+type reply[Resp any] struct {
+	Value Resp
+	Err   string
+}
+
+// NewTask registers fn as a task with the given name.
+//
+// Tasks need to be registered the same way in the parent and in the
+// worker processes, before [Main] is called.  The simplest way to
+// ensure this is to call NewTask in the initialization of
+// package-level variables.
+//
+// The request and response types need to be encodable with
+// [encoding/gob].  Errors returned by fn are passed to the parent
+// process as strings.  NewTask panics if a task with the same name is
+// already registered.
+func NewTask[Req, Resp any](name string, fn func(Req) (Resp, error)) *Task[Req, Resp] {
+	tasksMu.Lock()
+	defer tasksMu.Unlock()
+
+	if _, ok := tasks[name]; ok {
+		panic(fmt.Sprintf("worker: task %q registered twice", name))
+	}
+	tasks[name] = func(r io.Reader, w io.Writer) error {
+		return serve(r, w, fn)
+	}
+	return &Task[Req, Resp]{name: name}
+}
+
+// Name returns the name of the task.
+func (t *Task[Req, Resp]) Name() string {
+	return t.name
+}
+
+// Main runs the worker loop and exits if the current process was
+// started as a worker process by a [Pool].  Otherwise, it returns
+// immediately.
+//
+// Main needs to be called at the start of the program's main
+// function (or TestMain, in tests), after all tasks are registered.
+// It calls [landlock.RunExecHelper] first, which puts the Landlock
+// restrictions in place before the worker loop runs.
+func Main() {
+	landlock.RunExecHelper()
+
+	v, ok := os.LookupEnv(workerEnv)
+	if !ok {
+		return
+	}
+	os.Unsetenv(workerEnv)
+
+	if err := runWorker(v); err != nil {
+		fmt.Fprintf(os.Stderr, "go-landlock worker: %v\n", err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func runWorker(task string) error {
+	tasksMu.Lock()
+	run, ok := tasks[task]
+	tasksMu.Unlock()
+	if !ok {
+		return fmt.Errorf("unknown task %q", task)
+	}
+
+	in := os.NewFile(workerInFD, "worker-requests")
+	out := os.NewFile(workerOutFD, "worker-responses")
+	defer in.Close()
+	defer out.Close()
+	return run(in, out)
+}
+
+// serve handles requests read from r until r is closed.
+func serve[Req, Resp any](r io.Reader, w io.Writer, fn func(Req) (Resp, error)) error {
+	dec := gob.NewDecoder(r)
+	enc := gob.NewEncoder(w)
+	for {
+		var req Req
+		if err := dec.Decode(&req); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil // The pool closed the worker.
+			}
+			return fmt.Errorf("decoding request: %w", err)
+		}
+		var rep reply[Resp]
+		v, err := fn(req)
+		if err != nil {
+			rep.Err = err.Error()
+		} else {
+			rep.Value = v
+		}
+		if err := enc.Encode(&rep); err != nil {
+			return fmt.Errorf("encoding response: %w", err)
+		}
+	}
+}
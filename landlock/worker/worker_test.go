@@ -0,0 +1,205 @@
+//go:build linux
+
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/landlock-lsm/go-landlock/landlock"
+	"github.com/landlock-lsm/go-landlock/landlock/lltest"
+	"github.com/landlock-lsm/go-landlock/landlock/worker"
+)
+
+var (
+	upperTask = worker.NewTask("upper", func(s string) (string, error) {
+		if s == "" {
+			return "", errors.New("empty input")
+		}
+		return strings.ToUpper(s), nil
+	})
+
+	readTask = worker.NewTask("read", func(path string) ([]byte, error) {
+		return os.ReadFile(path)
+	})
+
+	pidTask = worker.NewTask("pid", func(encodable) (int, error) {
+		return os.Getpid(), nil
+	})
+
+	crashTask = worker.NewTask("crash", func(mode string) (int, error) {
+		switch mode {
+		case "exit":
+			os.Exit(3)
+		case "hang":
+			// Not select {}, which the runtime reports as a
+			// deadlock when there are no other threads.
+			time.Sleep(time.Hour)
+		}
+		return os.Getpid(), nil
+	})
+)
+
+// encodable is a request type which fails to encode if Fail is set.
+type encodable struct {
+	Fail bool
+}
+
+func (e encodable) GobEncode() ([]byte, error) {
+	if e.Fail {
+		return nil, errors.New("cannot encode")
+	}
+	return []byte{0}, nil
+}
+
+func (e *encodable) GobDecode([]byte) error {
+	return nil
+}
+
+func TestMain(m *testing.M) {
+	worker.Main()
+	os.Exit(m.Run())
+}
+
+func TestPoolCall(t *testing.T) {
+	pool, err := worker.NewPool(upperTask, worker.Options{Config: landlock.V1.BestEffort(), Size: 2})
+	if err != nil {
+		t.Fatalf("NewPool(): %v", err)
+	}
+	defer pool.Close()
+
+	got, err := pool.Call(context.Background(), "hello")
+	if err != nil || got != "HELLO" {
+		t.Errorf("Call(hello) = %q, %v, want HELLO", got, err)
+	}
+
+	_, err = pool.Call(context.Background(), "")
+	var taskErr *worker.TaskError
+	if !errors.As(err, &taskErr) || taskErr.Msg != "empty input" {
+		t.Errorf("Call(\"\") = %v, want TaskError", err)
+	}
+
+	if err := pool.Close(); err != nil {
+		t.Errorf("Close(): %v", err)
+	}
+	if _, err := pool.Call(context.Background(), "x"); !errors.Is(err, worker.ErrClosed) {
+		t.Errorf("Call() after Close() = %v, want ErrClosed", err)
+	}
+}
+
+func TestPoolRestricted(t *testing.T) {
+	lltest.RequireABI(t, 1)
+
+	dir := t.TempDir()
+	allowed := filepath.Join(dir, "allowed")
+	denied := filepath.Join(dir, "denied")
+	for _, p := range []string{allowed, denied} {
+		if err := os.WriteFile(p, []byte("data"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pool, err := worker.NewPool(readTask, worker.Options{
+		Config: landlock.V1,
+		Rules:  []landlock.Rule{landlock.ROFiles(allowed)},
+		Size:   1,
+	})
+	if err != nil {
+		t.Fatalf("NewPool(): %v", err)
+	}
+	defer pool.Close()
+
+	if got, err := pool.Call(context.Background(), allowed); err != nil || string(got) != "data" {
+		t.Errorf("Call(allowed) = %q, %v, want \"data\"", got, err)
+	}
+	if _, err := pool.Call(context.Background(), denied); err == nil {
+		t.Errorf("Call(denied) successful, want error")
+	}
+	// The parent process is not restricted.
+	if _, err := os.ReadFile(denied); err != nil {
+		t.Errorf("ReadFile(denied) in parent: %v", err)
+	}
+}
+
+func TestPoolRestartAfterCrash(t *testing.T) {
+	pool, err := worker.NewPool(crashTask, worker.Options{Config: landlock.V1.BestEffort(), Size: 1})
+	if err != nil {
+		t.Fatalf("NewPool(): %v", err)
+	}
+	defer pool.Close()
+
+	pid1, err := pool.Call(context.Background(), "pid")
+	if err != nil {
+		t.Fatalf("Call(pid): %v", err)
+	}
+	if _, err := pool.Call(context.Background(), "exit"); !errors.Is(err, worker.ErrCrashed) {
+		t.Errorf("Call(exit) = %v, want ErrCrashed", err)
+	}
+	pid2, err := pool.Call(context.Background(), "pid")
+	if err != nil {
+		t.Fatalf("Call(pid) after crash: %v", err)
+	}
+	if pid1 == pid2 {
+		t.Errorf("worker was not restarted, pid %v", pid1)
+	}
+}
+
+func TestPoolTimeout(t *testing.T) {
+	pool, err := worker.NewPool(crashTask, worker.Options{
+		Config:  landlock.V1.BestEffort(),
+		Size:    1,
+		Timeout: 100 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewPool(): %v", err)
+	}
+	defer pool.Close()
+
+	if _, err := pool.Call(context.Background(), "hang"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Call(hang) = %v, want DeadlineExceeded", err)
+	}
+	if _, err := pool.Call(context.Background(), "pid"); err != nil {
+		t.Errorf("Call(pid) after timeout: %v", err)
+	}
+
+}
+
+func TestPoolKeepsWorker(t *testing.T) {
+	pool, err := worker.NewPool(pidTask, worker.Options{Config: landlock.V1.BestEffort(), Size: 1})
+	if err != nil {
+		t.Fatalf("NewPool(): %v", err)
+	}
+	defer pool.Close()
+
+	pid1, err := pool.Call(context.Background(), encodable{})
+	if err != nil {
+		t.Fatalf("Call(): %v", err)
+	}
+
+	// Neither a canceled context nor a request which can not be
+	// encoded affect the worker.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for range 10 {
+		if _, err := pool.Call(ctx, encodable{}); !errors.Is(err, context.Canceled) {
+			t.Errorf("Call() with canceled context = %v, want Canceled", err)
+		}
+	}
+	_, err = pool.Call(context.Background(), encodable{Fail: true})
+	if err == nil || errors.Is(err, worker.ErrCrashed) || !strings.Contains(err.Error(), "cannot encode") {
+		t.Errorf("Call() with unencodable request = %v, want encoding error", err)
+	}
+
+	pid2, err := pool.Call(context.Background(), encodable{})
+	if err != nil {
+		t.Fatalf("Call(): %v", err)
+	}
+	if pid1 != pid2 {
+		t.Errorf("worker was restarted, pid %v != %v", pid1, pid2)
+	}
+}